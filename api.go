@@ -0,0 +1,236 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	apiDefaultPageSize = 50
+	apiMaxPageSize     = 200
+)
+
+// apiStory is a story as returned by the JSON API.
+type apiStory struct {
+	HnId   uint64    `json:"hn_id"`
+	Title  string    `json:"title"`
+	Time   uint64    `json:"time"`
+	Kind   StoryKind `json:"kind"`
+	Jobs   uint64    `json:"jobs"`
+	Seen   uint64    `json:"seen"`
+	Unseen uint64    `json:"unseen"`
+}
+
+// apiJob is a job as returned by the JSON API.
+type apiJob struct {
+	HnId      uint64 `json:"hn_id"`
+	StoryHnId uint64 `json:"story_hn_id"`
+	Text      string `json:"text"`
+	Time      uint64 `json:"time"`
+	Status    string `json:"status"`
+	Seen      bool   `json:"seen"`
+	Saved     bool   `json:"saved"`
+	Notes     string `json:"notes"`
+	EditedAt  uint64 `json:"edited_at,omitempty"`
+}
+
+func newAPIJob(j HnJob) apiJob {
+	return apiJob{
+		HnId:      j.HnId,
+		StoryHnId: j.StoryId,
+		Text:      j.Text,
+		Time:      j.Time,
+		Status:    jobStatusName(j.Status),
+		Seen:      j.Seen == 1,
+		Saved:     j.Saved == 1,
+		Notes:     j.Notes,
+		EditedAt:  j.EditedAt,
+	}
+}
+
+// apiJobPatch is the body of a PATCH /api/v1/jobs/{hnId} request.
+type apiJobPatch struct {
+	Seen  *bool   `json:"seen"`
+	Saved *bool   `json:"saved"`
+	Notes *string `json:"notes"`
+}
+
+// registerAPIRoutes registers the JSON API handler funcs on mux.
+func (s *Server) registerAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/stories", s.apiStoriesHandler)
+	mux.HandleFunc("GET /api/v1/stories/{storyId}/jobs", s.apiStoryJobsHandler)
+	mux.HandleFunc("GET /api/v1/jobs/{hnId}", s.apiJobHandler)
+	mux.HandleFunc("PATCH /api/v1/jobs/{hnId}", s.apiPatchJobHandler)
+}
+
+// apiStoriesHandler returns every story with its job counts, newest first.
+// Stories can be narrowed down to a kind with the kind query param.
+func (s *Server) apiStoriesHandler(w http.ResponseWriter, r *http.Request) {
+	var kind *StoryKind
+	if name := r.URL.Query().Get("kind"); name != "" {
+		k, err := parseStoryKind(name)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest)
+			return
+		}
+		kind = &k
+	}
+
+	stories, err := s.store.GetStoriesWithStats()
+	if err != nil {
+		log.Println("failed to select hiring stories:", err)
+		writeAPIError(w, http.StatusInternalServerError)
+		return
+	}
+
+	resp := struct {
+		Stories []apiStory `json:"stories"`
+	}{Stories: make([]apiStory, 0, len(stories))}
+	for _, st := range stories {
+		if kind != nil && st.Kind != *kind {
+			continue
+		}
+		resp.Stories = append(resp.Stories, apiStory{
+			HnId:   st.HnId,
+			Title:  st.Title,
+			Time:   st.Time,
+			Kind:   st.Kind,
+			Jobs:   st.Jobs,
+			Seen:   st.Seen,
+			Unseen: st.Unseen(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiStoryJobsHandler returns a page of the OK jobs of a story, newest first.
+// Pages are selected with the cursor and limit query params, and jobs can be
+// narrowed down with the same params as the web UI filters.
+func (s *Server) apiStoryJobsHandler(w http.ResponseWriter, r *http.Request) {
+	storyId, err := strconv.ParseUint(r.PathValue("storyId"), 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	cursor := s.parseUint64OrDefault(q.Get("cursor"), 0)
+	limit := int(min(max(s.parseUint64OrDefault(q.Get("limit"), apiDefaultPageSize), 1), apiMaxPageSize))
+
+	if _, err := s.store.GetStory(storyId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, http.StatusNotFound)
+			return
+		}
+		log.Println("failed to select hiring story:", err)
+		writeAPIError(w, http.StatusInternalServerError)
+		return
+	}
+
+	// One more job than asked for tells whether there is a next page.
+	jobs, err := s.store.GetJobsPage(storyId, cursor, limit+1, JobFilterFromQuery(q))
+	if err != nil {
+		log.Println("failed to select hiring jobs:", err)
+		writeAPIError(w, http.StatusInternalServerError)
+		return
+	}
+
+	resp := struct {
+		Jobs       []apiJob `json:"jobs"`
+		NextCursor uint64   `json:"next_cursor,omitempty"`
+	}{Jobs: make([]apiJob, 0, len(jobs))}
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+		resp.NextCursor = jobs[limit-1].HnId
+	}
+	for _, j := range jobs {
+		resp.Jobs = append(resp.Jobs, newAPIJob(j))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// apiJobHandler returns a single job.
+func (s *Server) apiJobHandler(w http.ResponseWriter, r *http.Request) {
+	hnId, err := strconv.ParseUint(r.PathValue("hnId"), 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest)
+		return
+	}
+
+	s.writeAPIJob(w, hnId)
+}
+
+// apiPatchJobHandler updates the seen, saved and notes fields of a job and
+// returns the updated job.
+func (s *Server) apiPatchJobHandler(w http.ResponseWriter, r *http.Request) {
+	hnId, err := strconv.ParseUint(r.PathValue("hnId"), 10, 64)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest)
+		return
+	}
+
+	var body apiJobPatch
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "invalid body: " + err.Error()})
+		return
+	}
+
+	patch := JobPatch{Seen: body.Seen, Saved: body.Saved, Notes: body.Notes}
+	if patch.IsEmpty() {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "body must set seen, saved or notes"})
+		return
+	}
+
+	if err := s.store.UpdateJob(hnId, patch); err != nil {
+		if errors.Is(err, ZeroRowsUpdated) {
+			writeAPIError(w, http.StatusNotFound)
+			return
+		}
+		log.Println("failed to update hiring job:", err)
+		writeAPIError(w, http.StatusInternalServerError)
+		return
+	}
+
+	s.writeAPIJob(w, hnId)
+}
+
+func (s *Server) writeAPIJob(w http.ResponseWriter, hnId uint64) {
+	job, err := s.store.GetJob(hnId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeAPIError(w, http.StatusNotFound)
+			return
+		}
+		log.Println("failed to select hiring job:", err)
+		writeAPIError(w, http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIJob(*job))
+}
+
+// apiError is the body of JSON API error responses.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// writeAPIError writes an error response with the status text as message.
+func writeAPIError(w http.ResponseWriter, status int) {
+	writeJSON(w, status, apiError{Error: http.StatusText(status)})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("failed to encode json response:", err)
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setUpAPIServer returns a Server with a story holding jobs 1 to n.
+func setUpAPIServer(t *testing.T, n uint64) (*Server, *HnStory) {
+	db := setupTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	store := &HNStore{db: db}
+	story := &HnStory{HnId: 100, Title: "test story", Time: 1000}
+	if err := store.CreateStory(story); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+	for id := uint64(1); id <= n; id++ {
+		job := &HnJob{HnId: id, Text: fmt.Sprintf("job %d", id), Time: 1000 + id, Status: jobStatusOk}
+		if err := store.CreateJob(job, story.HnId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+
+	return &Server{store: store, hnStory: story}, story
+}
+
+func serveAPI(t *testing.T, s *Server, method, url, body string, v any) int {
+	rr := httptest.NewRecorder()
+	s.GetMux().ServeHTTP(rr, httptest.NewRequest(method, url, strings.NewReader(body)))
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: expected json content type, got %q", method, url, ct)
+	}
+	if v != nil && rr.Code == http.StatusOK {
+		if err := json.NewDecoder(rr.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, url, err)
+		}
+	}
+	return rr.Code
+}
+
+func TestAPI_stories(t *testing.T) {
+	s, story := setUpAPIServer(t, 2)
+
+	var resp struct {
+		Stories []apiStory `json:"stories"`
+	}
+	if code := serveAPI(t, s, "GET", "/api/v1/stories", "", &resp); code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, code)
+	}
+
+	expected := []apiStory{{HnId: story.HnId, Title: story.Title, Time: story.Time, Jobs: 2, Unseen: 2}}
+	if !reflect.DeepEqual(resp.Stories, expected) {
+		t.Errorf("expected %+v, got %+v", expected, resp.Stories)
+	}
+}
+
+func TestAPI_storiesOfKind(t *testing.T) {
+	s, story := setUpAPIServer(t, 1)
+	hired := &HnStory{HnId: 200, Title: "Ask HN: Who wants to be hired?", Time: 2000, Kind: storyKindWantsToBeHired}
+	if err := s.store.CreateStory(hired); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+
+	tests := []struct {
+		kind     string
+		expected []uint64
+	}{
+		{"", []uint64{hired.HnId, story.HnId}},
+		{"hiring", []uint64{story.HnId}},
+		{"wants-to-be-hired", []uint64{hired.HnId}},
+		{"freelancer", nil},
+	}
+	for _, tt := range tests {
+		var resp struct {
+			Stories []apiStory `json:"stories"`
+		}
+		if code := serveAPI(t, s, "GET", "/api/v1/stories?kind="+tt.kind, "", &resp); code != http.StatusOK {
+			t.Fatalf("kind %q: expected status code %d, got %d", tt.kind, http.StatusOK, code)
+		}
+		var ids []uint64
+		for _, st := range resp.Stories {
+			ids = append(ids, st.HnId)
+		}
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Errorf("kind %q: expected stories %v, got %v", tt.kind, tt.expected, ids)
+		}
+	}
+
+	if code := serveAPI(t, s, "GET", "/api/v1/stories?kind=interns", "", nil); code != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, code)
+	}
+}
+
+func TestAPI_storyJobs(t *testing.T) {
+	s, story := setUpAPIServer(t, 5)
+
+	type page struct {
+		Jobs       []apiJob `json:"jobs"`
+		NextCursor uint64   `json:"next_cursor"`
+	}
+
+	t.Run("paginates_with_cursor", func(t *testing.T) {
+		var ids []uint64
+		url := fmt.Sprintf("/api/v1/stories/%d/jobs?limit=2", story.HnId)
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("too many pages")
+			}
+			var p page
+			if code := serveAPI(t, s, "GET", url, "", &p); code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d", http.StatusOK, code)
+			}
+			for _, j := range p.Jobs {
+				ids = append(ids, j.HnId)
+			}
+			if p.NextCursor == 0 {
+				break
+			}
+			url = fmt.Sprintf("/api/v1/stories/%d/jobs?limit=2&cursor=%d", story.HnId, p.NextCursor)
+		}
+
+		if expected := []uint64{5, 4, 3, 2, 1}; !reflect.DeepEqual(ids, expected) {
+			t.Errorf("expected job ids %v, got %v", expected, ids)
+		}
+	})
+
+	t.Run("applies_filters", func(t *testing.T) {
+		if err := s.store.SetJobAsSeen(5); err != nil {
+			t.Fatalf("SetJobAsSeen() failed: %v", err)
+		}
+		var p page
+		serveAPI(t, s, "GET", fmt.Sprintf("/api/v1/stories/%d/jobs?unseen=1&limit=1", story.HnId), "", &p)
+		if len(p.Jobs) != 1 || p.Jobs[0].HnId != 4 || p.NextCursor != 4 {
+			t.Errorf("expected unseen job 4 with next cursor 4, got %+v", p)
+		}
+	})
+
+	t.Run("unknown_story", func(t *testing.T) {
+		if code := serveAPI(t, s, "GET", "/api/v1/stories/999/jobs", "", nil); code != http.StatusNotFound {
+			t.Errorf("expected status code %d, got %d", http.StatusNotFound, code)
+		}
+	})
+
+	t.Run("invalid_story_id", func(t *testing.T) {
+		if code := serveAPI(t, s, "GET", "/api/v1/stories/abc/jobs", "", nil); code != http.StatusBadRequest {
+			t.Errorf("expected status code %d, got %d", http.StatusBadRequest, code)
+		}
+	})
+}
+
+func TestAPI_job(t *testing.T) {
+	s, story := setUpAPIServer(t, 1)
+
+	var job apiJob
+	if code := serveAPI(t, s, "GET", "/api/v1/jobs/1", "", &job); code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, code)
+	}
+	expected := apiJob{HnId: 1, StoryHnId: story.HnId, Text: "job 1", Time: 1001, Status: "ok"}
+	if job != expected {
+		t.Errorf("expected %+v, got %+v", expected, job)
+	}
+
+	if code := serveAPI(t, s, "GET", "/api/v1/jobs/999", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, code)
+	}
+}
+
+func TestAPI_patchJob(t *testing.T) {
+	s, _ := setUpAPIServer(t, 1)
+
+	var job apiJob
+	code := serveAPI(t, s, "PATCH", "/api/v1/jobs/1", `{"seen":true,"saved":true,"notes":"applied 10/17"}`, &job)
+	if code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, code)
+	}
+	if !job.Seen || !job.Saved || job.Notes != "applied 10/17" {
+		t.Errorf("expected job to be updated, got %+v", job)
+	}
+
+	code = serveAPI(t, s, "PATCH", "/api/v1/jobs/1", `{"saved":false}`, &job)
+	if code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, code)
+	}
+	if !job.Seen || job.Saved || job.Notes != "applied 10/17" {
+		t.Errorf("expected only saved to change, got %+v", job)
+	}
+
+	tests := []struct {
+		name, url, body string
+		expected        int
+	}{
+		{"unknown_job", "/api/v1/jobs/999", `{"seen":true}`, http.StatusNotFound},
+		{"invalid_id", "/api/v1/jobs/abc", `{"seen":true}`, http.StatusBadRequest},
+		{"invalid_json", "/api/v1/jobs/1", `{"seen":`, http.StatusBadRequest},
+		{"unknown_field", "/api/v1/jobs/1", `{"status":"dead"}`, http.StatusBadRequest},
+		{"empty_patch", "/api/v1/jobs/1", `{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := serveAPI(t, s, "PATCH", tt.url, tt.body, nil); code != tt.expected {
+				t.Errorf("expected status code %d, got %d", tt.expected, code)
+			}
+		})
+	}
+}
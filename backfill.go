@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+type HeaderBackfillProcess struct {
+	store *HNStore
+}
+
+func NewHeaderBackfillProcess(store *HNStore) *HeaderBackfillProcess {
+	return &HeaderBackfillProcess{store: store}
+}
+
+// Run parses and saves the header fields of every stored job. Jobs that fail
+// don't stop the others, but make Run return an error.
+func (b *HeaderBackfillProcess) Run() error {
+	log.Println("starting header backfill...")
+
+	jobs, err := b.store.GetAllJobs()
+	if err != nil {
+		return fmt.Errorf("failed to get jobs: %w", err)
+	}
+
+	failed := 0
+	for _, job := range jobs {
+		if err := b.store.SaveJobHeader(job.HnId, ParseJobHeader(job.Text)); err != nil {
+			log.Printf("failed to save job header %d: %v", job.HnId, err)
+			failed++
+		}
+	}
+
+	log.Printf("parsed headers of %d jobs, %d failed", len(jobs)-failed, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to backfill %d of %d job headers", failed, len(jobs))
+	}
+	return nil
+}
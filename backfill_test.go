@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestHeaderBackfillProcess_Run(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	setUpStoryWithJob(t, store)
+
+	if err := NewHeaderBackfillProcess(store).Run(); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	// saving the roles of a job fails without their table
+	if _, err := db.Exec(`DROP TABLE hiring_job_role`); err != nil {
+		t.Fatalf("failed to drop hiring_job_role: %v", err)
+	}
+	err := NewHeaderBackfillProcess(store).Run()
+	if err == nil || err.Error() != "failed to backfill 1 of 1 job headers" {
+		t.Fatalf("expected the failed job to be reported, got %v", err)
+	}
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/rand/v2"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 )
 
@@ -14,6 +19,15 @@ type ApiStory struct {
 	Title string   `json:"title"`
 	Time  uint64   `json:"time"`
 	Kids  []uint64 `json:"kids"`
+	// Dead and Deleted are set on stories killed or removed by moderators.
+	Dead    bool `json:"dead"`
+	Deleted bool `json:"deleted"`
+}
+
+// Kind returns the kind of whoishiring thread the story is, and false if it
+// isn't one.
+func (s ApiStory) Kind() (StoryKind, bool) {
+	return classifyStoryTitle(s.Title)
 }
 
 // ApiJob represents a Hacker News job response item.
@@ -38,10 +52,55 @@ func (j *ApiJob) StatusToDbValue() uint8 {
 	return jobStatusOk
 }
 
+// Errors returned by the Client, wrapped with the details of the request.
+var (
+	// ErrNotFound is returned when the API responds with 404.
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when the API still responds with 429 after
+	// all retries.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrNullItem is returned when the API responds with a null body, which
+	// is what Hacker News does for items that don't exist.
+	ErrNullItem = errors.New("null item")
+)
+
+// RetryPolicy controls how failed requests are retried. Network errors, 429
+// and 5xx responses are retried with exponential backoff and jitter, or after
+// the delay given by the Retry-After header.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// defaultRetryPolicy is the RetryPolicy used by NewClient.
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// delay returns how long to wait before the next attempt. retryAfter is the
+// delay requested by the server, if any.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return min(retryAfter, p.MaxDelay)
+	}
+
+	backoff := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+	if backoff <= 0 {
+		return 0
+	}
+	// Equal jitter: wait at least half of the backoff.
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
 // Client is a client for the Hacker News API.
 type Client struct {
 	httpClient *http.Client
 	baseUrl    string
+	retry      RetryPolicy
 }
 
 // NewClient create new Hacker News API client.
@@ -49,80 +108,119 @@ func NewClient(baseUrl string) *Client {
 	return &Client{
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 		baseUrl:    baseUrl,
+		retry:      defaultRetryPolicy,
 	}
 }
 
-// GetStory fetches a Hacker News story by id.
-func (c *Client) GetStory(id uint64) (*ApiStory, error) {
-	url := fmt.Sprintf("%s/item/%d.json", c.baseUrl, id)
-	resp, err := c.httpClient.Get(url)
+// getJSON gets url and decodes the JSON response into v, retrying as set by
+// the client RetryPolicy. Retries stop as soon as ctx is done.
+func (c *Client) getJSON(ctx context.Context, url string, v any) error {
+	for attempt := 1; ; attempt++ {
+		retryAfter, retry, err := c.tryGetJSON(ctx, url, v)
+		if err == nil || !retry || attempt >= c.retry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(c.retry.delay(attempt, retryAfter))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// tryGetJSON makes a single request. It returns whether the request should
+// be retried and the delay asked for by the server.
+func (c *Client) tryGetJSON(ctx context.Context, url string, v any) (time.Duration, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get story %d: %w", id, err)
+		return 0, false, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, true, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HackerNews API returned status %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, false, ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), true, ErrRateLimited
+	case resp.StatusCode >= 500:
+		return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), true,
+			fmt.Errorf("HackerNews API returned status %d", resp.StatusCode)
+	default:
+		return 0, false, fmt.Errorf("HackerNews API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to read response: %w", err)
+	}
+	if string(bytes.TrimSpace(body)) == "null" {
+		return 0, false, ErrNullItem
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return 0, false, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	return 0, false, nil
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as
+// an http date. It returns 0 when the header is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// GetStory fetches a Hacker News story by id.
+func (c *Client) GetStory(ctx context.Context, id uint64) (*ApiStory, error) {
+	url := fmt.Sprintf("%s/item/%d.json", c.baseUrl, id)
+
 	var story ApiStory
-	if err := json.NewDecoder(resp.Body).Decode(&story); err != nil {
-		return nil, fmt.Errorf("failed to decode story %d: %w", id, err)
+	if err := c.getJSON(ctx, url, &story); err != nil {
+		return nil, fmt.Errorf("failed to get story %d: %w", id, err)
 	}
 
 	return &story, nil
 }
 
-// GetJob fetches a Hacker News story by id.
-func (c *Client) GetJob(id uint64) (*ApiJob, error) {
+// GetJob fetches a Hacker News job by id.
+func (c *Client) GetJob(ctx context.Context, id uint64) (*ApiJob, error) {
 	url := fmt.Sprintf("%s/item/%d.json", c.baseUrl, id)
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get job %d: %w", id, err)
-	}
-	defer resp.Body.Close()
 
 	var job ApiJob
-	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
-		return nil, fmt.Errorf("failed to decode job %d: %w", id, err)
+	if err := c.getJSON(ctx, url, &job); err != nil {
+		return nil, fmt.Errorf("failed to get job %d: %w", id, err)
 	}
 
 	return &job, nil
 }
 
 // GetWhoIsHiringSubmissionIds fetches story IDs from user whoishiring.
-func (c *Client) GetWhoIsHiringSubmissionIds() ([]uint64, error) {
+func (c *Client) GetWhoIsHiringSubmissionIds(ctx context.Context) ([]uint64, error) {
 	url := fmt.Sprintf("%s/user/whoishiring.json", c.baseUrl)
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get whoishiring user: %w", err)
-	}
-	defer resp.Body.Close()
 
 	var user struct {
 		Submitted []uint64 `json:"submitted"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
-		return nil, fmt.Errorf("failed to decode whoishiring user: %w", err)
+	if err := c.getJSON(ctx, url, &user); err != nil {
+		return nil, fmt.Errorf("failed to get whoishiring user: %w", err)
 	}
 
 	return user.Submitted, nil
 }
-
-// FindWhoIsHiringStory fetches the latest WhoIsHiring story.
-func (c *Client) FindWhoIsHiringStory(storyIds []uint64) (*ApiStory, error) {
-	for _, id := range storyIds {
-		story, err := c.GetStory(id)
-		if err != nil {
-			fmt.Printf("failed to get story %d: %v\n", id, err)
-			continue
-		}
-
-		if strings.HasPrefix(story.Title, "Ask HN: Who is hiring?") {
-			return story, nil
-		}
-	}
-
-	return nil, fmt.Errorf("no 'Who is hiring?' story found in the provided IDs")
-}
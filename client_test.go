@@ -1,14 +1,25 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
+// newTestClient returns a Client that retries without waiting long.
+func newTestClient(baseUrl string) *Client {
+	c := NewClient(baseUrl)
+	c.retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	return c
+}
+
 func TestApiJob_StatusToDbValue(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -42,6 +53,31 @@ func TestApiJob_StatusToDbValue(t *testing.T) {
 	}
 }
 
+func TestApiStory_Kind(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected StoryKind
+		ok       bool
+	}{
+		{"Ask HN: Who is hiring? (October 2026)", storyKindHiring, true},
+		{"Ask HN: Who wants to be hired? (October 2026)", storyKindWantsToBeHired, true},
+		{"Ask HN: Freelancer? Seeking freelancer? (October 2026)", storyKindFreelancer, true},
+		{"Ask HN: Who is hiring? (October 2026) [repost]", storyKindHiring, true},
+		{"Ask HN: Who is hiring interns?", storyKindHiring, false},
+		{"Tell HN: Who is hiring? threads are moving", storyKindHiring, false},
+		{"", storyKindHiring, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			kind, ok := ApiStory{Title: tt.title}.Kind()
+			if ok != tt.ok || (ok && kind != tt.expected) {
+				t.Errorf("expected %s, %t, got %s, %t", tt.expected, tt.ok, kind, ok)
+			}
+		})
+	}
+}
+
 func TestClient_GetStory(t *testing.T) {
 	t.Run("handle_ok_response", func(t *testing.T) {
 		testID := uint64(1)
@@ -70,7 +106,7 @@ func TestClient_GetStory(t *testing.T) {
 		defer server.Close()
 
 		client := NewClient(server.URL)
-		story, err := client.GetStory(testID)
+		story, err := client.GetStory(context.Background(), testID)
 		if err != nil {
 			t.Fatalf("expected no error, got: %v", err)
 		}
@@ -90,8 +126,8 @@ func TestClient_GetStory(t *testing.T) {
 		)
 		defer server.Close()
 
-		client := NewClient(server.URL)
-		story, err := client.GetStory(1)
+		client := newTestClient(server.URL)
+		story, err := client.GetStory(context.Background(), 1)
 
 		if err == nil {
 			t.Fatal("expected an error, got nil")
@@ -110,7 +146,7 @@ func TestClient_GetStory(t *testing.T) {
 		defer server.Close()
 
 		client := NewClient(server.URL)
-		story, err := client.GetStory(1)
+		story, err := client.GetStory(context.Background(), 1)
 
 		if err == nil {
 			t.Fatal("expected an error, got nil")
@@ -120,3 +156,210 @@ func TestClient_GetStory(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_GetJob(t *testing.T) {
+	tests := []struct {
+		name string
+		// responses are returned in order, the last one is repeated.
+		responses    []func(w http.ResponseWriter)
+		expectedErr  error
+		expectedHits int64
+	}{
+		{
+			name: "ok",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { fmt.Fprint(w, `{"id":1,"text":"job"}`) },
+			},
+			expectedHits: 1,
+		},
+		{
+			name: "retry_server_error",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { fmt.Fprint(w, `{"id":1,"text":"job"}`) },
+			},
+			expectedHits: 2,
+		},
+		{
+			name: "retry_rate_limited_with_retry_after",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) { fmt.Fprint(w, `{"id":1,"text":"job"}`) },
+			},
+			expectedHits: 2,
+		},
+		{
+			name: "give_up_when_rate_limited",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusTooManyRequests) },
+			},
+			expectedErr:  ErrRateLimited,
+			expectedHits: 3,
+		},
+		{
+			name: "not_found_is_not_retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			},
+			expectedErr:  ErrNotFound,
+			expectedHits: 1,
+		},
+		{
+			name: "null_item",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { fmt.Fprint(w, "null") },
+			},
+			expectedErr:  ErrNullItem,
+			expectedHits: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(hits.Add(1))
+				tt.responses[min(n, len(tt.responses))-1](w)
+			}))
+			defer server.Close()
+
+			job, err := newTestClient(server.URL).GetJob(context.Background(), 1)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				if job != nil {
+					t.Errorf("expected nil job on error, got %+v", job)
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			} else if job.Id != 1 || job.Text != "job" {
+				t.Errorf("unexpected job %+v", job)
+			}
+
+			if h := hits.Load(); h != tt.expectedHits {
+				t.Errorf("expected %d requests, got %d", tt.expectedHits, h)
+			}
+		})
+	}
+}
+
+func TestClient_GetWhoIsHiringSubmissionIds(t *testing.T) {
+	t.Run("handle_ok_response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/user/whoishiring.json" {
+				t.Errorf("unexpected path %q", r.URL.Path)
+			}
+			fmt.Fprint(w, `{"id":"whoishiring","submitted":[3,2,1]}`)
+		}))
+		defer server.Close()
+
+		ids, err := newTestClient(server.URL).GetWhoIsHiringSubmissionIds(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !reflect.DeepEqual(ids, []uint64{3, 2, 1}) {
+			t.Errorf("unexpected ids %v", ids)
+		}
+	})
+
+	t.Run("handle_bad_status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"submitted":[1]}`)
+		}))
+		defer server.Close()
+
+		if _, err := newTestClient(server.URL).GetWhoIsHiringSubmissionIds(context.Background()); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"Sat, 17 Oct 2026 12:00:30 GMT", 30 * time.Second},
+		{"Sat, 17 Oct 2026 11:00:00 GMT", 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.expected {
+			t.Errorf("parseRetryAfter(%q) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		backoff := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+		for range 20 {
+			d := p.delay(attempt, 0)
+			if d < backoff/2 || d > backoff {
+				t.Fatalf("attempt %d: delay %v not in [%v, %v]", attempt, d, backoff/2, backoff)
+			}
+		}
+	}
+
+	if d := p.delay(1, 500*time.Millisecond); d != 500*time.Millisecond {
+		t.Errorf("expected Retry-After delay of 500ms, got %v", d)
+	}
+	if d := p.delay(1, time.Minute); d != p.MaxDelay {
+		t.Errorf("expected Retry-After delay to be capped at %v, got %v", p.MaxDelay, d)
+	}
+}
+
+func TestClient_ContextCancellation(t *testing.T) {
+	t.Run("stops_retrying", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(server.URL)
+		client.retry = RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Minute}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, err := client.GetJob(ctx, 1); err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected GetJob to return once ctx is done, took %v", elapsed)
+		}
+	})
+
+	t.Run("aborts_in_flight_request", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := newTestClient(server.URL).GetStory(ctx, 1)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,508 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// commandNames lists the commands in the order they are shown in the usage.
+var commandNames = []string{
+	"sync",
+	"serve",
+	"verify",
+	"search",
+	"export",
+	"import",
+	"stats",
+	"migrate",
+	"backfill-headers",
+}
+
+// commands returns a new set of the available commands.
+func commands() map[string]*command {
+	return map[string]*command{
+		"sync":             newSyncCommand(),
+		"serve":            newServeCommand(),
+		"verify":           newVerifyCommand(),
+		"search":           newSearchCommand(),
+		"export":           newExportCommand(),
+		"import":           newImportCommand(),
+		"stats":            newStatsCommand(),
+		"migrate":          newMigrateCommand(),
+		"backfill-headers": newBackfillHeadersCommand(),
+	}
+}
+
+// fetchOptions are the flags controlling how fast jobs are fetched from the
+// Hacker News API.
+type fetchOptions struct {
+	concurrency int
+	rate        float64
+}
+
+func defaultFetchOptions() *fetchOptions {
+	return &fetchOptions{concurrency: defaultFetchConcurrency, rate: defaultFetchRate}
+}
+
+// addFetchFlags registers the fetch option flags on fs.
+func addFetchFlags(fs *flag.FlagSet) *fetchOptions {
+	o := defaultFetchOptions()
+	fs.IntVar(&o.concurrency, "concurrency", o.concurrency, "maximum number of concurrent API requests")
+	fs.Float64Var(&o.rate, "rate", o.rate, "maximum API requests per second, 0 for no limit")
+	return o
+}
+
+func (o *fetchOptions) validate() error {
+	if o.concurrency < 1 {
+		return usageError{"concurrency must be greater than 0"}
+	}
+	if o.rate < 0 {
+		return usageError{"rate must not be negative"}
+	}
+	return nil
+}
+
+func newSyncCommand() *command {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	opts := addFetchFlags(fs)
+	backfill := fs.Bool("backfill", false, "fetch every past story instead of the latest ones")
+	since := fs.String("since", "", "with -backfill, only fetch the stories from this month on, as YYYY-MM")
+	window := fs.Int("window", defaultStoryWindow, "number of the latest whoishiring submissions searched for the current stories")
+
+	return &command{
+		fs:      fs,
+		summary: "Fetch the latest story of every kind and its new jobs.",
+		run: func(env *commandEnv) error {
+			if !*backfill {
+				if *since != "" {
+					return usageError{"-since requires -backfill"}
+				}
+				if *window < 1 {
+					return usageError{"window must be greater than 0"}
+				}
+				return runSync(env, opts, *window)
+			}
+
+			var sinceMonth time.Time
+			if *since != "" {
+				var err error
+				sinceMonth, err = time.Parse(monthLayout, *since)
+				if err != nil {
+					return usageError{fmt.Sprintf("invalid month %q, expected YYYY-MM", *since)}
+				}
+			}
+			return runBackfill(env, opts, sinceMonth)
+		},
+	}
+}
+
+func newVerifyCommand() *command {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	opts := addFetchFlags(fs)
+	storyId := fs.Uint64("story", 0, "verify the jobs of this story id")
+	from := fs.String("from", "", "verify the jobs of stories posted on or after this date (YYYY-MM-DD)")
+	to := fs.String("to", "", "verify the jobs of stories posted on or before this date (YYYY-MM-DD)")
+	stalest := fs.Int("stalest", 0, "verify the N jobs that haven't been verified for the longest")
+	history := fs.Bool("history", false, "print the results of the latest verify runs instead")
+
+	return &command{
+		fs:      fs,
+		summary: "Check that the jobs of a story are still OK.",
+		run: func(env *commandEnv) error {
+			if *history {
+				return printVerifyRuns(env.store, env.stdout, 10)
+			}
+
+			target, err := parseVerifyTarget(*storyId, *from, *to, *stalest)
+			if err != nil {
+				return err
+			}
+			return runVerify(env, opts, target)
+		},
+	}
+}
+
+// parseVerifyTarget creates a VerifyTarget from the verify flags. Only one
+// kind of target may be set.
+func parseVerifyTarget(storyId uint64, from, to string, stalest int) (VerifyTarget, error) {
+	var t VerifyTarget
+	kinds := 0
+
+	if storyId > 0 {
+		t.StoryId = storyId
+		kinds++
+	}
+
+	if from != "" || to != "" {
+		for _, d := range []struct {
+			value string
+			field *time.Time
+		}{{from, &t.From}, {to, &t.To}} {
+			if d.value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.DateOnly, d.value)
+			if err != nil {
+				return VerifyTarget{}, usageError{fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", d.value)}
+			}
+			*d.field = parsed
+		}
+		if !t.From.IsZero() && !t.To.IsZero() && t.To.Before(t.From) {
+			return VerifyTarget{}, usageError{"-to must not be before -from"}
+		}
+		kinds++
+	}
+
+	if stalest < 0 {
+		return VerifyTarget{}, usageError{"stalest must not be negative"}
+	}
+	if stalest > 0 {
+		t.Stalest = stalest
+		kinds++
+	}
+
+	if kinds > 1 {
+		return VerifyTarget{}, usageError{"only one of -story, -from/-to and -stalest can be set"}
+	}
+
+	return t, nil
+}
+
+func runSync(env *commandEnv, opts *fetchOptions, window int) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	client := NewClient(env.cfg.BaseURL)
+	sp := NewSyncProcess(env.store, client, NewFetcher(client, opts.concurrency, opts.rate))
+	sp.window = window
+	return sp.Run(env.ctx)
+}
+
+func runBackfill(env *commandEnv, opts *fetchOptions, since time.Time) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	client := NewClient(env.cfg.BaseURL)
+	sp := NewSyncProcess(env.store, client, NewFetcher(client, opts.concurrency, opts.rate))
+	return sp.Backfill(env.ctx, since)
+}
+
+func runVerify(env *commandEnv, opts *fetchOptions, target VerifyTarget) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	client := NewClient(env.cfg.BaseURL)
+	v := NewVerifyProcess(env.store, NewFetcher(client, opts.concurrency, opts.rate))
+	return v.Run(env.ctx, target)
+}
+
+// serveOptions are the flags of the serve command.
+type serveOptions struct {
+	autoSync   time.Duration
+	autoVerify time.Duration
+	fetch      *fetchOptions
+}
+
+func newServeCommand() *command {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	opts := &serveOptions{fetch: addFetchFlags(fs)}
+	fs.DurationVar(&opts.autoSync, "auto-sync", 0, "sync in the background on this interval, e.g. 1h")
+	fs.DurationVar(&opts.autoVerify, "auto-verify", 0, "verify the latest story in the background on this interval, e.g. 6h")
+
+	return &command{
+		fs:      fs,
+		summary: "Run the web server.",
+		run: func(env *commandEnv) error {
+			return runServe(env, opts)
+		},
+	}
+}
+
+func runServe(env *commandEnv, opts *serveOptions) error {
+	if opts.autoSync < 0 || opts.autoVerify < 0 {
+		return usageError{"auto-sync and auto-verify intervals must not be negative"}
+	}
+	if err := opts.fetch.validate(); err != nil {
+		return err
+	}
+
+	client := NewClient(env.cfg.BaseURL)
+	fetcher := NewFetcher(client, opts.fetch.concurrency, opts.fetch.rate)
+	syncStories := func(ctx context.Context) error {
+		return NewSyncProcess(env.store, client, fetcher).Run(ctx)
+	}
+
+	// The server needs a story to start with, so fill an empty database
+	// first instead of waiting for the scheduler.
+	synced := false
+	if opts.autoSync > 0 {
+		if _, err := env.store.GetLatestStory(); errors.Is(err, sql.ErrNoRows) {
+			log.Println("no story saved yet, syncing before starting the server")
+			if err := syncStories(env.ctx); err != nil {
+				return err
+			}
+			synced = true
+		}
+	}
+
+	server, err := InitializeNewServer(env.store)
+	if err != nil {
+		return err
+	}
+
+	if opts.autoSync > 0 || opts.autoVerify > 0 {
+		scheduler := NewScheduler()
+		syncTask := func(ctx context.Context) error {
+			if err := syncStories(ctx); err != nil {
+				return err
+			}
+			return server.Refresh()
+		}
+		switch {
+		case synced:
+			// don't sync again right after the sync above
+			scheduler.EveryLater("sync", opts.autoSync, syncTask)
+		case opts.autoSync > 0:
+			scheduler.Every("sync", opts.autoSync, syncTask)
+		}
+		if opts.autoVerify > 0 {
+			scheduler.Every("verify", opts.autoVerify, func(ctx context.Context) error {
+				return NewVerifyProcess(env.store, fetcher).Run(ctx, VerifyTarget{})
+			})
+		}
+		server.scheduler = scheduler
+
+		// Stop the tasks if the server fails to start, then wait for them.
+		ctx, cancel := context.WithCancel(env.ctx)
+		wait := scheduler.Start(ctx)
+		defer wait()
+		defer cancel()
+		return server.Run(ctx, env.cfg.Addr)
+	}
+
+	return server.Run(env.ctx, env.cfg.Addr)
+}
+
+func runBackfillHeaders(env *commandEnv) error {
+	return NewHeaderBackfillProcess(env.store).Run()
+}
+
+func newBackfillHeadersCommand() *command {
+	return &command{
+		fs:      flag.NewFlagSet("backfill-headers", flag.ContinueOnError),
+		summary: "Parse the header fields of all saved jobs.",
+		run:     runBackfillHeaders,
+	}
+}
+
+func newSearchCommand() *command {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	storyId := fs.Uint64("story", 0, "only search the jobs of this story id")
+	limit := fs.Int("limit", 20, "maximum number of results")
+
+	return &command{
+		fs:      fs,
+		summary: "Search the job posts of all stories.",
+		args:    "<query>",
+		run: func(env *commandEnv) error {
+			query := strings.Join(env.args, " ")
+			if strings.TrimSpace(query) == "" {
+				return usageError{"search query is required"}
+			}
+			if *limit < 1 {
+				return usageError{"limit must be greater than 0"}
+			}
+			return printSearchResults(env.store, env.stdout, query, *storyId, *limit)
+		},
+	}
+}
+
+// printSearchResults prints the top job posts matching query.
+func printSearchResults(store *HNStore, w io.Writer, query string, storyId uint64, limit int) error {
+	results, err := store.SearchJobs(query, storyId, limit, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(w, "No jobs found.")
+		return nil
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\n  https://news.ycombinator.com/item?id=%d\n  %s\n\n",
+			r.StoryTitle, r.HnId, r.SnippetText("\033[1m", "\033[0m"))
+	}
+
+	return nil
+}
+
+func newExportCommand() *command {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	storyId := fs.Uint64("story", 0, "story id to export, defaults to the latest story")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	format := fs.String("format", string(exportJSONLFormat), "export format: jsonl, csv or md")
+	seen := fs.String("seen", "", "only export seen (true) or unseen (false) jobs")
+	saved := fs.String("saved", "", "only export saved (true) or unsaved (false) jobs")
+	status := fs.String("status", "all", "only export jobs with this status: ok, dead, deleted or removed")
+
+	return &command{
+		fs:      fs,
+		summary: "Export the jobs of a story as JSON Lines, CSV or Markdown.",
+		run: func(env *commandEnv) error {
+			f, err := parseExportFormat(*format)
+			if err != nil {
+				return usageError{err.Error()}
+			}
+			filter, err := parseExportFilter(*seen, *saved, *status)
+			if err != nil {
+				return usageError{err.Error()}
+			}
+
+			story, err := storyOrLatest(env.store, *storyId)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := env.store.GetExportJobs(story.HnId, filter)
+			if err != nil {
+				return err
+			}
+
+			if *output == "" {
+				return writeExport(env.stdout, f, story, jobs)
+			}
+
+			file, err := os.Create(*output)
+			if err != nil {
+				return fmt.Errorf("failed to create export file: %w", err)
+			}
+			if err := writeExport(file, f, story, jobs); err != nil {
+				file.Close()
+				return err
+			}
+			if err := file.Close(); err != nil {
+				return fmt.Errorf("failed to close export file: %w", err)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newImportCommand() *command {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+
+	return &command{
+		fs:      fs,
+		summary: "Merge JSON Lines exports, never clearing seen, saved or notes.",
+		args:    "<file>...",
+		run: func(env *commandEnv) error {
+			if len(env.args) == 0 {
+				return usageError{"at least one file to import is required"}
+			}
+
+			for _, name := range env.args {
+				if err := importFile(env.store, env.stdout, name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// importFile imports the JSON Lines export in the file name and prints what
+// it changed.
+func importFile(store *HNStore, w io.Writer, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	res, err := importJSONL(store, f)
+	if err != nil {
+		return fmt.Errorf("failed to import %s after %s: %w", name, res, err)
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", name, res)
+	return nil
+}
+
+// storyOrLatest returns the story with hnStoryId, or the latest "Who is
+// hiring?" story when hnStoryId is 0.
+func storyOrLatest(store *HNStore, hnStoryId uint64) (*HnStory, error) {
+	var story *HnStory
+	var err error
+	if hnStoryId == 0 {
+		story, err = store.GetLatestStory()
+	} else {
+		story, err = store.GetStory(hnStoryId)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("story not found, run \"whoishiring sync\" first")
+	}
+	return story, err
+}
+
+func newStatsCommand() *command {
+	return &command{
+		fs:      flag.NewFlagSet("stats", flag.ContinueOnError),
+		summary: "Print job counts of every story.",
+		run: func(env *commandEnv) error {
+			stories, err := env.store.GetStoriesWithStats()
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintln(tw, "STORY\tKIND\tDATE\tJOBS\tSEEN\tUNSEEN\t")
+			for _, s := range stories {
+				date := time.Unix(int64(s.Time), 0).UTC().Format("2006-01-02")
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%d\t\n", s.HnId, s.Kind, date, s.Jobs, s.Seen, s.Unseen())
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newMigrateCommand() *command {
+	return &command{
+		fs:          flag.NewFlagSet("migrate", flag.ContinueOnError),
+		summary:     "Apply pending database migrations, or print their status.",
+		args:        "[up|status]",
+		skipMigrate: true,
+		run: func(env *commandEnv) error {
+			action := "up"
+			if len(env.args) > 0 {
+				action = env.args[0]
+			}
+
+			switch action {
+			case "up":
+				results, err := migrateUp(env.ctx, env.db)
+				if err != nil {
+					return err
+				}
+				for _, r := range results {
+					fmt.Fprintf(env.stdout, "applied migration %s\n", r.Source.Path)
+				}
+				return nil
+			case "status":
+				return printMigrationStatus(env.ctx, env.db, env.stdout)
+			default:
+				return usageError{fmt.Sprintf("unknown migrate action %q", action)}
+			}
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Config holds the settings shared by every mode of the app.
+//
+// Values are resolved in order of precedence: command line flags, then
+// WHOISHIRING_* environment variables, then the JSON config file, then the
+// defaults.
+type Config struct {
+	DBPath  string `json:"db"`
+	Addr    string `json:"addr"`
+	BaseURL string `json:"api_url"`
+}
+
+const (
+	envConfigPath = "WHOISHIRING_CONFIG"
+	envDBPath     = "WHOISHIRING_DB"
+	envAddr       = "WHOISHIRING_ADDR"
+	envBaseURL    = "WHOISHIRING_API_URL"
+)
+
+// defaultConfig returns the Config used when nothing else is set.
+func defaultConfig() Config {
+	return Config{
+		DBPath:  "whoishiring.db",
+		Addr:    ":8080",
+		BaseURL: "https://hacker-news.firebaseio.com/v0",
+	}
+}
+
+// addConfigFlags registers the Config flags on fs.
+func addConfigFlags(fs *flag.FlagSet) {
+	fs.String("config", "", "path to a JSON config file (env "+envConfigPath+")")
+	fs.String("db", "", "path to the sqlite database (env "+envDBPath+")")
+	fs.String("addr", "", "address the server listens on (env "+envAddr+")")
+	fs.String("api-url", "", "Hacker News API base url (env "+envBaseURL+")")
+}
+
+// loadConfig resolves the Config from the parsed flags of fs, the environment
+// and the optional config file.
+func loadConfig(fs *flag.FlagSet, getenv func(string) string) (*Config, error) {
+	cfg := defaultConfig()
+
+	setFlags := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = f.Value.String()
+	})
+
+	path := getenv(envConfigPath)
+	if v, ok := setFlags["config"]; ok {
+		path = v
+	}
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	overrides := []struct {
+		field *string
+		env   string
+		flag  string
+	}{
+		{&cfg.DBPath, envDBPath, "db"},
+		{&cfg.Addr, envAddr, "addr"},
+		{&cfg.BaseURL, envBaseURL, "api-url"},
+	}
+	for _, o := range overrides {
+		if v := getenv(o.env); v != "" {
+			*o.field = v
+		}
+		if v, ok := setFlags[o.flag]; ok {
+			*o.field = v
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// readFile overrides the config with the values set in a JSON file.
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("failed to decode config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// validate checks the config values and normalizes the api url.
+func (c *Config) validate() error {
+	var errs []error
+
+	if strings.TrimSpace(c.DBPath) == "" {
+		errs = append(errs, errors.New("db path must not be empty"))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("invalid addr %q: %w", c.Addr, err))
+	}
+
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("invalid api url %q: must be an absolute http(s) url", c.BaseURL))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
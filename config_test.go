@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(configFile, []byte(`{"db": "file.db", "addr": "127.0.0.1:9000"}`), 0o600)
+	if err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		expected Config
+	}{
+		{
+			name:     "defaults",
+			expected: defaultConfig(),
+		},
+		{
+			name: "config_file",
+			args: []string{"-config", configFile},
+			expected: Config{
+				DBPath:  "file.db",
+				Addr:    "127.0.0.1:9000",
+				BaseURL: defaultConfig().BaseURL,
+			},
+		},
+		{
+			name: "env_overrides_config_file",
+			env: map[string]string{
+				envConfigPath: configFile,
+				envDBPath:     "env.db",
+				envBaseURL:    "http://localhost:8081/v0/",
+			},
+			expected: Config{
+				DBPath:  "env.db",
+				Addr:    "127.0.0.1:9000",
+				BaseURL: "http://localhost:8081/v0",
+			},
+		},
+		{
+			name: "flags_override_env",
+			args: []string{"-db", "flag.db", "-addr", ":9999"},
+			env: map[string]string{
+				envDBPath: "env.db",
+				envAddr:   ":7777",
+			},
+			expected: Config{
+				DBPath:  "flag.db",
+				Addr:    ":9999",
+				BaseURL: defaultConfig().BaseURL,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			addConfigFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("failed to parse args: %v", err)
+			}
+
+			cfg, err := loadConfig(fs, func(k string) string { return tt.env[k] })
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if *cfg != tt.expected {
+				t.Fatalf("expected config %+v, got %+v", tt.expected, *cfg)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	unknownField := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(unknownField, []byte(`{"port": 8080}`), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{name: "empty_db", args: []string{"-db", " "}, expected: "db path must not be empty"},
+		{name: "invalid_addr", args: []string{"-addr", "8080"}, expected: `invalid addr "8080"`},
+		{name: "relative_api_url", args: []string{"-api-url", "localhost/v0"}, expected: "invalid api url"},
+		{name: "unknown_config_field", args: []string{"-config", unknownField}, expected: `unknown field "port"`},
+		{name: "missing_config_file", args: []string{"-config", "missing.json"}, expected: "failed to open config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			addConfigFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("failed to parse args: %v", err)
+			}
+
+			_, err := loadConfig(fs, func(string) string { return "" })
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Fatalf("expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
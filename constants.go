@@ -1,7 +1,131 @@
 package main
 
+import (
+	"fmt"
+	"strings"
+)
+
 const (
 	jobStatusOk      = 1
 	jobStatusDead    = 2
 	jobStatusDeleted = 3
+	// jobStatusRemoved is set on jobs missing from their story's kids, e.g.
+	// removed by a moderator.
+	jobStatusRemoved = 4
+)
+
+// jobStatusName returns a readable name for a job status.
+func jobStatusName(status uint8) string {
+	switch status {
+	case jobStatusOk:
+		return "ok"
+	case jobStatusDead:
+		return "dead"
+	case jobStatusDeleted:
+		return "deleted"
+	case jobStatusRemoved:
+		return "removed"
+	default:
+		return fmt.Sprintf("status %d", status)
+	}
+}
+
+// validJobStatus reports whether status is one of the known job statuses.
+func validJobStatus(status uint8) bool {
+	switch status {
+	case jobStatusOk, jobStatusDead, jobStatusDeleted, jobStatusRemoved:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseJobStatus returns the job status with the name returned by
+// jobStatusName.
+func parseJobStatus(name string) (uint8, error) {
+	for _, status := range []uint8{jobStatusOk, jobStatusDead, jobStatusDeleted, jobStatusRemoved} {
+		if strings.EqualFold(name, jobStatusName(status)) {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown job status %q, use ok, dead, deleted or removed", name)
+}
+
+// StoryKind is the kind of monthly thread posted by the whoishiring user.
+type StoryKind uint8
+
+const (
+	storyKindHiring StoryKind = iota
+	storyKindWantsToBeHired
+	storyKindFreelancer
 )
+
+// storyKinds lists every StoryKind in the order they are shown.
+var storyKinds = []StoryKind{storyKindHiring, storyKindWantsToBeHired, storyKindFreelancer}
+
+// storyKindTitlePrefixes are the title prefixes identifying each kind.
+var storyKindTitlePrefixes = map[StoryKind]string{
+	storyKindHiring:         "Ask HN: Who is hiring?",
+	storyKindWantsToBeHired: "Ask HN: Who wants to be hired?",
+	storyKindFreelancer:     "Ask HN: Freelancer? Seeking freelancer?",
+}
+
+// String returns the name of k used in flags, urls and JSON.
+func (k StoryKind) String() string {
+	switch k {
+	case storyKindHiring:
+		return "hiring"
+	case storyKindWantsToBeHired:
+		return "wants-to-be-hired"
+	case storyKindFreelancer:
+		return "freelancer"
+	default:
+		return fmt.Sprintf("kind %d", uint8(k))
+	}
+}
+
+// Label returns the title of the threads of kind k, for display.
+func (k StoryKind) Label() string {
+	switch k {
+	case storyKindWantsToBeHired:
+		return "Who wants to be hired?"
+	case storyKindFreelancer:
+		return "Freelancer?"
+	default:
+		return "Who is hiring?"
+	}
+}
+
+func (k StoryKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *StoryKind) UnmarshalText(text []byte) error {
+	kind, err := parseStoryKind(string(text))
+	if err != nil {
+		return err
+	}
+	*k = kind
+	return nil
+}
+
+// parseStoryKind returns the StoryKind named name.
+func parseStoryKind(name string) (StoryKind, error) {
+	for _, k := range storyKinds {
+		if strings.EqualFold(name, k.String()) {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown story kind %q, use hiring, wants-to-be-hired or freelancer", name)
+}
+
+// classifyStoryTitle returns the kind of the thread with title, and false if
+// title isn't the title of a whoishiring thread.
+func classifyStoryTitle(title string) (StoryKind, bool) {
+	for _, k := range storyKinds {
+		if strings.HasPrefix(title, storyKindTitlePrefixes[k]) {
+			return k, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// defaultStoryWindow is how many of the latest submissions of the
+// whoishiring user are searched for the current stories. It posts 2 or 3
+// stories a month, so this covers a few months even with re-posts.
+const defaultStoryWindow = 10
+
+// ErrStoryNotFound is returned when none of the searched submissions is a
+// story of the wanted kind.
+var ErrStoryNotFound = errors.New("story not found")
+
+// detectedStory is a monthly story found among the whoishiring submissions.
+type detectedStory struct {
+	*ApiStory
+	Kind StoryKind
+	// Month is the month the story is for, formatted with monthLayout.
+	Month string
+}
+
+// detectStories fetches the first s.window submissionIds and returns the
+// latest story of every kind found in them. Submissions that don't exist or
+// fail to be fetched are skipped. An error is only returned when ctx is
+// done, or when every submission failed to be fetched.
+func (s *SyncProcess) detectStories(ctx context.Context, submissionIds []uint64) (map[StoryKind]detectedStory, error) {
+	submissionIds = submissionIds[:min(s.window, len(submissionIds))]
+
+	var stories []*ApiStory
+	var fetchErr error
+	for _, id := range submissionIds {
+		story, err := s.client.GetStory(ctx, id)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		switch {
+		case errors.Is(err, ErrNullItem) || errors.Is(err, ErrNotFound):
+			log.Printf("skipping submission %d, it doesn't exist", id)
+			continue
+		case err != nil:
+			log.Printf("skipping submission %d: %v", id, err)
+			fetchErr = err
+			continue
+		}
+		stories = append(stories, story)
+	}
+	if len(stories) == 0 && fetchErr != nil {
+		return nil, fmt.Errorf("failed to fetch the latest submissions: %w", fetchErr)
+	}
+
+	return latestStories(stories), nil
+}
+
+// latestStories returns the latest story of every kind in stories, using
+// the month read from their titles rather than when they were posted, so
+// stories posted a few days early or late count for the right month. When
+// a story was posted again for the same month, the live one with the most
+// jobs is kept, then the latest one.
+func latestStories(stories []*ApiStory) map[StoryKind]detectedStory {
+	latest := make(map[StoryKind]detectedStory)
+	for _, story := range stories {
+		kind, ok := story.Kind()
+		if !ok || story.Dead || story.Deleted {
+			continue
+		}
+
+		found := detectedStory{ApiStory: story, Kind: kind, Month: storyMonth(story.Title, story.Time)}
+		if current, ok := latest[kind]; ok && !found.isNewerThan(current) {
+			continue
+		}
+		latest[kind] = found
+	}
+
+	return latest
+}
+
+// isNewerThan returns true if d should be synced instead of other.
+func (d detectedStory) isNewerThan(other detectedStory) bool {
+	if d.Month != other.Month {
+		return d.Month > other.Month
+	}
+	if len(d.Kids) != len(other.Kids) {
+		return len(d.Kids) > len(other.Kids)
+	}
+	return d.Time > other.Time
+}
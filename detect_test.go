@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// postedOn returns the unix time of a day of 2026 at 15:00 UTC.
+func postedOn(month time.Month, day int) uint64 {
+	return uint64(time.Date(2026, month, day, 15, 0, 0, 0, time.UTC).Unix())
+}
+
+func TestLatestStories(t *testing.T) {
+	tests := []struct {
+		name     string
+		stories  []*ApiStory
+		expected map[StoryKind]uint64
+	}{
+		{
+			name: "current_month",
+			stories: []*ApiStory{
+				{Id: 6, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1)},
+				{Id: 5, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: postedOn(time.October, 1)},
+				{Id: 4, Title: "Ask HN: Freelancer? Seeking freelancer? (October 2026)", Time: postedOn(time.October, 1)},
+				{Id: 3, Title: "Ask HN: Who is hiring? (September 2026)", Time: postedOn(time.September, 1)},
+				{Id: 2, Title: "Ask HN: Who wants to be hired? (September 2026)", Time: postedOn(time.September, 1)},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 6, storyKindWantsToBeHired: 5, storyKindFreelancer: 4},
+		},
+		{
+			name: "posted_early",
+			stories: []*ApiStory{
+				{Id: 6, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.September, 30)},
+				{Id: 3, Title: "Ask HN: Who is hiring? (September 2026)", Time: postedOn(time.September, 1)},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 6},
+		},
+		{
+			name: "posted_late",
+			stories: []*ApiStory{
+				{Id: 7, Title: "Ask HN: Who wants to be hired? (September 2026)", Time: postedOn(time.October, 3)},
+				{Id: 6, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1)},
+				{Id: 5, Title: "Ask HN: Who wants to be hired? (August 2026)", Time: postedOn(time.August, 1)},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 6, storyKindWantsToBeHired: 7},
+		},
+		{
+			name: "out_of_order_submissions",
+			stories: []*ApiStory{
+				{Id: 3, Title: "Ask HN: Who is hiring? (September 2026)", Time: postedOn(time.October, 2)},
+				{Id: 6, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1)},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 6},
+		},
+		{
+			name: "repost_of_dead_story",
+			stories: []*ApiStory{
+				{Id: 7, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1), Dead: true, Kids: []uint64{1, 2}},
+				{Id: 6, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1)},
+				{Id: 5, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: postedOn(time.October, 1), Deleted: true},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 6},
+		},
+		{
+			name: "repost_with_most_jobs",
+			stories: []*ApiStory{
+				{Id: 8, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 2), Kids: []uint64{1}},
+				{Id: 7, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1), Kids: []uint64{2, 3}},
+				{Id: 6, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: postedOn(time.October, 1)},
+				{Id: 9, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: postedOn(time.October, 2)},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 7, storyKindWantsToBeHired: 9},
+		},
+		{
+			name: "title_without_month",
+			stories: []*ApiStory{
+				{Id: 6, Title: "Ask HN: Who is hiring?", Time: postedOn(time.October, 1)},
+				{Id: 3, Title: "Ask HN: Who is hiring? (September 2026)", Time: postedOn(time.September, 1)},
+			},
+			expected: map[StoryKind]uint64{storyKindHiring: 6},
+		},
+		{
+			name: "other_submissions",
+			stories: []*ApiStory{
+				{Id: 6, Title: "Tell HN: Who is hiring? threads will be posted on Mondays", Time: postedOn(time.October, 1)},
+				{Id: 5, Title: "Ask HN: Who is hiring interns?", Time: postedOn(time.October, 1)},
+			},
+			expected: map[StoryKind]uint64{},
+		},
+		{
+			name:     "no_submissions",
+			stories:  nil,
+			expected: map[StoryKind]uint64{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := map[StoryKind]uint64{}
+			for kind, story := range latestStories(tt.stories) {
+				res[kind] = story.Id
+			}
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestSyncProcess_Run(t *testing.T) {
+	october := &ApiStory{Id: 20, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1), Kids: []uint64{21}}
+	hired := &ApiStory{Id: 19, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: postedOn(time.October, 1) - 60, Kids: []uint64{22}}
+	september := &ApiStory{Id: 10, Title: "Ask HN: Who is hiring? (September 2026)", Time: postedOn(time.September, 1), Kids: []uint64{11}}
+	repost := &ApiStory{Id: 23, Title: october.Title, Time: postedOn(time.October, 2)}
+	items := map[uint64]any{
+		23: repost,
+		20: october,
+		19: hired,
+		10: september,
+		21: ApiJob{Id: 21, Text: "Acme | Go | Remote"},
+		22: ApiJob{Id: 22, Text: "Location: Berlin | Remote: Yes"},
+		11: ApiJob{Id: 11, Text: "Initech | Rust | Berlin"},
+	}
+
+	tests := []struct {
+		name      string
+		stored    []*HnStory
+		submitted []uint64
+		window    int
+		// expected are the stories stored after the sync, newest first.
+		expected []string
+		// current is the "Who is hiring?" story served after the sync.
+		current uint64
+		err     error
+	}{
+		{
+			name:      "first_run",
+			submitted: []uint64{20, 19},
+			window:    defaultStoryWindow,
+			expected:  []string{"20:hiring", "19:wants-to-be-hired"},
+			current:   20,
+		},
+		{
+			name:      "first_run_without_stories",
+			submitted: []uint64{99},
+			window:    defaultStoryWindow,
+			err:       ErrStoryNotFound,
+		},
+		{
+			name:      "no_submissions",
+			submitted: []uint64{},
+			window:    defaultStoryWindow,
+			err:       ErrStoryNotFound,
+		},
+		{
+			name:      "new_month",
+			stored:    []*HnStory{{HnId: 10, Title: september.Title, Time: september.Time}},
+			submitted: []uint64{20, 19, 99, 10},
+			window:    defaultStoryWindow,
+			expected:  []string{"20:hiring", "19:wants-to-be-hired", "10:hiring"},
+			current:   20,
+		},
+		{
+			name:      "new_month_not_posted_yet",
+			stored:    []*HnStory{{HnId: 20, Title: october.Title, Time: october.Time}},
+			submitted: []uint64{10},
+			window:    defaultStoryWindow,
+			expected:  []string{"20:hiring"},
+			current:   20,
+		},
+		{
+			name:      "repost_with_fewer_jobs",
+			stored:    []*HnStory{{HnId: 23, Title: repost.Title, Time: repost.Time}},
+			submitted: []uint64{23, 20, 19},
+			window:    defaultStoryWindow,
+			expected:  []string{"23:hiring", "20:hiring", "19:wants-to-be-hired"},
+			current:   20,
+		},
+		{
+			name:      "story_outside_window",
+			submitted: []uint64{99, 98, 20},
+			window:    2,
+			err:       ErrStoryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+			store := &HNStore{db: db}
+			for _, story := range tt.stored {
+				if err := store.CreateStory(story); err != nil {
+					t.Fatalf("CreateStory() failed: %v", err)
+				}
+			}
+
+			server := newFakeHN(t, tt.submitted, items, nil)
+			defer server.Close()
+
+			client := newTestClient(server.URL)
+			sp := NewSyncProcess(store, client, NewFetcher(client, 1, 0))
+			sp.window = tt.window
+
+			err := sp.Run(context.Background())
+			requests := server.Requests()
+			for _, id := range tt.submitted[min(tt.window, len(tt.submitted)):] {
+				if slices.Contains(requests, fmt.Sprintf("/item/%d.json", id)) {
+					t.Errorf("expected submission %d outside the window not to be fetched", id)
+				}
+			}
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected error %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+
+			stories, err := store.GetStoriesWithStats()
+			if err != nil {
+				t.Fatalf("GetStoriesWithStats() failed: %v", err)
+			}
+			var res []string
+			for _, s := range stories {
+				res = append(res, fmt.Sprintf("%d:%s", s.HnId, s.Kind))
+			}
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Errorf("expected stories %v, got %v", tt.expected, res)
+			}
+
+			current, err := store.GetLatestStory()
+			if err != nil {
+				t.Fatalf("GetLatestStory() failed: %v", err)
+			}
+			if current.HnId != tt.current {
+				t.Errorf("expected current story %d, got %d", tt.current, current.HnId)
+			}
+		})
+	}
+}
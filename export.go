@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFormat is a file format jobs can be exported to.
+type exportFormat string
+
+const (
+	exportJSONLFormat    exportFormat = "jsonl"
+	exportCSVFormat      exportFormat = "csv"
+	exportMarkdownFormat exportFormat = "md"
+)
+
+// parseExportFormat returns the export format named s.
+func parseExportFormat(s string) (exportFormat, error) {
+	switch f := exportFormat(strings.ToLower(s)); f {
+	case exportJSONLFormat, exportCSVFormat, exportMarkdownFormat:
+		return f, nil
+	case "markdown":
+		return exportMarkdownFormat, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q, use jsonl, csv or md", s)
+	}
+}
+
+// ContentType returns the media type of files in format f.
+func (f exportFormat) ContentType() string {
+	switch f {
+	case exportCSVFormat:
+		return "text/csv; charset=utf-8"
+	case exportMarkdownFormat:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "application/jsonl; charset=utf-8"
+	}
+}
+
+// ExportFilter narrows down the exported jobs. Nil fields don't exclude any
+// jobs.
+type ExportFilter struct {
+	Seen   *bool
+	Saved  *bool
+	Status *uint8
+}
+
+// parseExportFilter creates an ExportFilter from the seen, saved and status
+// values of the export command flags or the /export query params. Empty
+// values don't filter, and a status of "all" is the same as no status.
+func parseExportFilter(seen, saved, status string) (ExportFilter, error) {
+	var f ExportFilter
+	var err error
+	if f.Seen, err = parseOptionalBool("seen", seen); err != nil {
+		return ExportFilter{}, err
+	}
+	if f.Saved, err = parseOptionalBool("saved", saved); err != nil {
+		return ExportFilter{}, err
+	}
+	if status != "" && status != "all" {
+		s, err := parseJobStatus(status)
+		if err != nil {
+			return ExportFilter{}, err
+		}
+		f.Status = &s
+	}
+	return f, nil
+}
+
+// ExportFilterFromQuery creates an ExportFilter from url query params, e.g.
+// "?seen=0&saved=1&status=ok".
+func ExportFilterFromQuery(q url.Values) (ExportFilter, error) {
+	return parseExportFilter(q.Get("seen"), q.Get("saved"), q.Get("status"))
+}
+
+func parseOptionalBool(name, value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value %q, use true or false", name, value)
+	}
+	return &b, nil
+}
+
+// whereClause returns the sql conditions matching f, each starting with
+// " and ", along with their args.
+func (f ExportFilter) whereClause() (string, []any) {
+	var sb strings.Builder
+	var args []any
+	if f.Seen != nil {
+		sb.WriteString(" and seen=?")
+		args = append(args, *f.Seen)
+	}
+	if f.Saved != nil {
+		sb.WriteString(" and saved=?")
+		args = append(args, *f.Saved)
+	}
+	if f.Status != nil {
+		sb.WriteString(" and status=?")
+		args = append(args, *f.Status)
+	}
+	return sb.String(), args
+}
+
+// ExportJob is a job as written to export files. The story fields let an
+// import create the story of the job.
+type ExportJob struct {
+	HnId       uint64    `json:"hn_id"`
+	StoryHnId  uint64    `json:"story_hn_id"`
+	StoryTitle string    `json:"story_title,omitempty"`
+	StoryTime  uint64    `json:"story_time,omitempty"`
+	StoryKind  StoryKind `json:"story_kind"`
+	Text       string    `json:"text"`
+	Time       uint64    `json:"time"`
+	Status     uint8     `json:"status"`
+	Seen       bool      `json:"seen"`
+	Saved      bool      `json:"saved"`
+	Notes      string    `json:"notes,omitempty"`
+}
+
+// writeExport writes the jobs of story to w in format f.
+func writeExport(w io.Writer, f exportFormat, story *HnStory, jobs []HnJob) error {
+	switch f {
+	case exportCSVFormat:
+		return exportCSV(w, story, jobs)
+	case exportMarkdownFormat:
+		return exportMarkdown(w, story, jobs)
+	default:
+		return exportJSONL(w, story, jobs)
+	}
+}
+
+// exportJSONL writes the jobs of story to w, one JSON object per line.
+func exportJSONL(w io.Writer, story *HnStory, jobs []HnJob) error {
+	enc := json.NewEncoder(w)
+	for _, j := range jobs {
+		err := enc.Encode(ExportJob{
+			HnId:       j.HnId,
+			StoryHnId:  story.HnId,
+			StoryTitle: story.Title,
+			StoryTime:  story.Time,
+			StoryKind:  story.Kind,
+			Text:       j.Text,
+			Time:       j.Time,
+			Status:     j.Status,
+			Seen:       j.Seen == 1,
+			Saved:      j.Saved == 1,
+			Notes:      j.Notes,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write job %d: %w", j.HnId, err)
+		}
+	}
+
+	return nil
+}
+
+var exportCSVHeader = []string{
+	"hn_id", "story_hn_id", "url", "posted_at", "status", "seen", "saved", "header", "text", "notes",
+}
+
+// exportCSV writes the jobs of story to w as CSV, with their text as plain
+// text so it reads well in spreadsheets.
+func exportCSV(w io.Writer, story *HnStory, jobs []HnJob) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, j := range jobs {
+		err := cw.Write([]string{
+			strconv.FormatUint(j.HnId, 10),
+			strconv.FormatUint(story.HnId, 10),
+			hnItemURL(j.HnId),
+			exportTime(j.Time),
+			jobStatusName(j.Status),
+			strconv.FormatBool(j.Seen == 1),
+			strconv.FormatBool(j.Saved == 1),
+			headerLine(j.Text),
+			renderJobPlain(j.Text),
+			j.Notes,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write job %d: %w", j.HnId, err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportMarkdown writes a digest of the jobs of story to w, with a section
+// per job, to be shared in docs.
+func exportMarkdown(w io.Writer, story *HnStory, jobs []HnJob) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", markdownEscaper.Replace(story.Title))
+	fmt.Fprintf(&b, "%d jobs from <%s>\n", len(jobs), hnItemURL(story.HnId))
+
+	for _, j := range jobs {
+		title := headerLine(j.Text)
+		if title == "" {
+			title = fmt.Sprintf("Job %d", j.HnId)
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", markdownEscaper.Replace(title))
+
+		details := []string{
+			fmt.Sprintf("[Hacker News](%s)", hnItemURL(j.HnId)),
+			"posted " + time.Unix(int64(j.Time), 0).UTC().Format(time.DateOnly),
+		}
+		if j.Status != jobStatusOk {
+			details = append(details, jobStatusName(j.Status))
+		}
+		if j.Saved == 1 {
+			details = append(details, "saved")
+		}
+		b.WriteString(strings.Join(details, " · ") + "\n")
+
+		if text := renderJobMarkdown(j.Text); text != "" {
+			b.WriteString("\n" + text + "\n")
+		}
+		if notes := strings.TrimSpace(j.Notes); notes != "" {
+			b.WriteString("\n> **Notes:** " + strings.ReplaceAll(markdownEscaper.Replace(notes), "\n", "\n> ") + "\n")
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write markdown export: %w", err)
+	}
+	return nil
+}
+
+// hnItemURL returns the Hacker News page of an item.
+func hnItemURL(hnId uint64) string {
+	return fmt.Sprintf("https://news.ycombinator.com/item?id=%d", hnId)
+}
+
+func exportTime(t uint64) string {
+	return time.Unix(int64(t), 0).UTC().Format(time.RFC3339)
+}
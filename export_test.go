@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportJSONL(t *testing.T) {
+	story := &HnStory{HnId: 1, Title: "test story", Time: 100}
+	jobs := []HnJob{
+		{HnId: 3, Text: "job 3", Time: 300, Status: jobStatusOk, Seen: 1},
+		{HnId: 2, Text: "job 2\nline", Time: 200, Status: jobStatusDead, Saved: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := exportJSONL(&buf, story, jobs); err != nil {
+		t.Fatalf("exportJSONL() failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(jobs) {
+		t.Fatalf("expected %d lines, got %d", len(jobs), len(lines))
+	}
+
+	expected := []ExportJob{
+		{HnId: 3, StoryHnId: 1, StoryTitle: "test story", StoryTime: 100, Text: "job 3", Time: 300, Status: jobStatusOk, Seen: true},
+		{HnId: 2, StoryHnId: 1, StoryTitle: "test story", StoryTime: 100, Text: "job 2\nline", Time: 200, Status: jobStatusDead, Saved: true},
+	}
+	for i, line := range lines {
+		var got ExportJob
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("failed to decode line %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, expected[i]) {
+			t.Fatalf("expected job %+v, got %+v", expected[i], got)
+		}
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	story := &HnStory{HnId: 1, Title: "test story", Time: 100}
+	jobs := []HnJob{
+		{HnId: 3, Text: "Acme | Go | Remote<p>We use <i>Go</i>, &quot;sqlite&quot;", Time: 300, Status: jobStatusOk, Saved: 1, Notes: "apply"},
+	}
+
+	var buf bytes.Buffer
+	if err := exportCSV(&buf, story, jobs); err != nil {
+		t.Fatalf("exportCSV() failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected := [][]string{
+		exportCSVHeader,
+		{"3", "1", "https://news.ycombinator.com/item?id=3", "1970-01-01T00:05:00Z", "ok", "false", "true",
+			"Acme | Go | Remote", "Acme | Go | Remote\n\nWe use Go, \"sqlite\"", "apply"},
+	}
+	if !reflect.DeepEqual(records, expected) {
+		t.Fatalf("expected records %q, got %q", expected, records)
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	story := &HnStory{HnId: 1, Title: "Ask HN: Who is hiring? (October 2026)", Time: 100}
+	jobs := []HnJob{
+		{HnId: 3, Text: `Acme | Go | Remote<p>Apply at <a href="https://acme.com">our site</a>`, Time: 300, Status: jobStatusOk, Saved: 1, Notes: "ask about\nvisa"},
+		{HnId: 2, Text: "", Time: 200, Status: jobStatusDead},
+	}
+
+	var buf bytes.Buffer
+	if err := exportMarkdown(&buf, story, jobs); err != nil {
+		t.Fatalf("exportMarkdown() failed: %v", err)
+	}
+
+	expected := `# Ask HN: Who is hiring? (October 2026)
+
+2 jobs from <https://news.ycombinator.com/item?id=1>
+
+## Acme | Go | Remote
+
+[Hacker News](https://news.ycombinator.com/item?id=3) · posted 1970-01-01 · saved
+
+Acme | Go | Remote
+
+Apply at [our site](https://acme.com)
+
+> **Notes:** ask about
+> visa
+
+## Job 2
+
+[Hacker News](https://news.ycombinator.com/item?id=2) · posted 1970-01-01 · dead
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestParseExportFilter(t *testing.T) {
+	yes, no, dead := true, false, uint8(jobStatusDead)
+	tests := []struct {
+		name                string
+		seen, saved, status string
+		expected            ExportFilter
+		expectedErr         bool
+	}{
+		{name: "empty", status: "", expected: ExportFilter{}},
+		{name: "all_statuses", status: "all", expected: ExportFilter{}},
+		{name: "every_filter", seen: "0", saved: "true", status: "Dead", expected: ExportFilter{Seen: &no, Saved: &yes, Status: &dead}},
+		{name: "invalid_bool", seen: "maybe", expectedErr: true},
+		{name: "invalid_status", status: "gone", expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseExportFilter(tt.seen, tt.saved, tt.status)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseExportFilter() failed: %v", err)
+			}
+			if !reflect.DeepEqual(f, tt.expected) {
+				t.Fatalf("expected filter %+v, got %+v", tt.expected, f)
+			}
+		})
+	}
+}
+
+func TestHNStore_GetExportJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	for _, j := range []*HnJob{
+		{HnId: 2, Text: "dead job", Status: jobStatusDead},
+		{HnId: 3, Text: "saved job", Status: jobStatusOk},
+	} {
+		if err := store.CreateJob(j, story.HnId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+	if err := store.SetJobSaved(3); err != nil {
+		t.Fatalf("SetJobSaved() failed: %v", err)
+	}
+
+	saved, ok := true, uint8(jobStatusOk)
+	tests := []struct {
+		name     string
+		filter   ExportFilter
+		expected []uint64
+	}{
+		{name: "no_filter", filter: ExportFilter{}, expected: []uint64{3, 2, job.HnId}},
+		{name: "ok_status", filter: ExportFilter{Status: &ok}, expected: []uint64{3, job.HnId}},
+		{name: "saved", filter: ExportFilter{Saved: &saved}, expected: []uint64{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs, err := store.GetExportJobs(story.HnId, tt.filter)
+			if err != nil {
+				t.Fatalf("GetExportJobs() failed: %v", err)
+			}
+			var ids []uint64
+			for _, j := range jobs {
+				ids = append(ids, j.HnId)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Fatalf("expected jobs %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFetchConcurrency = 8
+	defaultFetchRate        = 20
+)
+
+// Fetcher fetches Hacker News jobs with a bounded number of workers and a
+// rate limit shared by all of them, so large threads don't fire hundreds of
+// simultaneous requests at the API.
+type Fetcher struct {
+	client      *Client
+	concurrency int
+	limiter     *rateLimiter
+}
+
+// NewFetcher creates a Fetcher running at most concurrency requests at a
+// time and at most rate requests per second. A rate of 0 disables the rate
+// limit.
+func NewFetcher(client *Client, concurrency int, rate float64) *Fetcher {
+	return &Fetcher{
+		client:      client,
+		concurrency: max(concurrency, 1),
+		limiter:     newRateLimiter(rate),
+	}
+}
+
+// FetchJobs fetches the jobs with the given ids and calls handle with the
+// result of each one. handle is called from multiple goroutines. When ctx is
+// cancelled no new requests are started, requests aborted by the cancellation
+// are not handled, and FetchJobs returns ctx.Err() once the workers stop.
+func (f *Fetcher) FetchJobs(
+	ctx context.Context,
+	ids []uint64,
+	handle func(id uint64, job *ApiJob, err error),
+) error {
+	queue := make(chan uint64)
+	var wg sync.WaitGroup
+
+	for range min(f.concurrency, len(ids)) {
+		wg.Go(func() {
+			for id := range queue {
+				if err := f.limiter.Wait(ctx); err != nil {
+					return
+				}
+				job, err := f.client.GetJob(ctx, id)
+				if err != nil && ctx.Err() != nil {
+					return
+				}
+				handle(id, job, err)
+			}
+		})
+	}
+
+send:
+	for _, id := range ids {
+		select {
+		case queue <- id:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+// rateLimiter is a token bucket allowing rate requests per second with
+// bursts of up to one second worth of requests. A nil rateLimiter doesn't
+// limit anything.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	burst := max(rate, 1)
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
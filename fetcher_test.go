@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newJobServer returns a test server answering every item request with a job
+// after delay, and a func returning the peak number of in-flight requests.
+func newJobServer(t *testing.T, delay time.Duration) (*httptest.Server, func() int64) {
+	var inFlight, peak atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		time.Sleep(delay)
+
+		idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/item/"), ".json")
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(ApiJob{Id: id, Text: "job " + idStr})
+	}))
+	t.Cleanup(server.Close)
+	return server, peak.Load
+}
+
+func TestFetcher_FetchJobs(t *testing.T) {
+	t.Run("limits_in_flight_requests", func(t *testing.T) {
+		server, peak := newJobServer(t, 10*time.Millisecond)
+		f := NewFetcher(NewClient(server.URL), 3, 0)
+
+		var ids []uint64
+		for i := uint64(1); i <= 30; i++ {
+			ids = append(ids, i)
+		}
+
+		var mu sync.Mutex
+		fetched := map[uint64]bool{}
+		err := f.FetchJobs(context.Background(), ids, func(id uint64, job *ApiJob, err error) {
+			if err != nil {
+				t.Errorf("unexpected error for job %d: %v", id, err)
+				return
+			}
+			if job.Id != id {
+				t.Errorf("expected job %d, got %d", id, job.Id)
+			}
+			mu.Lock()
+			fetched[id] = true
+			mu.Unlock()
+		})
+		if err != nil {
+			t.Fatalf("FetchJobs failed: %v", err)
+		}
+
+		if len(fetched) != len(ids) {
+			t.Errorf("expected %d jobs fetched, got %d", len(ids), len(fetched))
+		}
+		if p := peak(); p > 3 {
+			t.Errorf("expected at most 3 requests in flight, got %d", p)
+		} else if p < 2 {
+			t.Errorf("expected requests to run concurrently, peak was %d", p)
+		}
+	})
+
+	t.Run("limits_request_rate", func(t *testing.T) {
+		server, _ := newJobServer(t, 0)
+		// 50 req/s with a burst of 50: the 10 requests after the burst take
+		// at least 200ms.
+		f := NewFetcher(NewClient(server.URL), 5, 50)
+
+		var ids []uint64
+		for i := uint64(1); i <= 60; i++ {
+			ids = append(ids, i)
+		}
+
+		start := time.Now()
+		err := f.FetchJobs(context.Background(), ids, func(uint64, *ApiJob, error) {})
+		if err != nil {
+			t.Fatalf("FetchJobs failed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("expected rate limit to slow down requests, took %v", elapsed)
+		}
+	})
+
+	t.Run("stops_when_context_is_cancelled", func(t *testing.T) {
+		server, _ := newJobServer(t, 5*time.Millisecond)
+		f := NewFetcher(NewClient(server.URL), 2, 0)
+
+		var ids []uint64
+		for i := uint64(1); i <= 100; i++ {
+			ids = append(ids, i)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var handled atomic.Int64
+		err := f.FetchJobs(ctx, ids, func(uint64, *ApiJob, error) {
+			if handled.Add(1) == 4 {
+				cancel()
+			}
+		})
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		if n := handled.Load(); n >= int64(len(ids)) {
+			t.Errorf("expected fetching to stop early, handled %d jobs", n)
+		}
+	})
+
+	t.Run("no_ids", func(t *testing.T) {
+		f := NewFetcher(NewClient("http://127.0.0.1:0"), 2, 10)
+		err := f.FetchJobs(context.Background(), nil, func(uint64, *ApiJob, error) {
+			t.Error("handle should not be called")
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Run("nil_limiter_never_blocks", func(t *testing.T) {
+		var l *rateLimiter
+		for range 100 {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	})
+
+	t.Run("returns_when_context_is_done", func(t *testing.T) {
+		l := newRateLimiter(1)
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := l.Wait(ctx); err != context.DeadlineExceeded {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
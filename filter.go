@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// JobFilter narrows down the jobs returned when browsing a story.
+type JobFilter struct {
+	Remote   bool
+	Unseen   bool
+	Location string
+	Keyword  string
+}
+
+// JobFilterFromQuery creates a JobFilter from url query params, e.g.
+// "?remote=1&location=berlin&unseen=1&keyword=go".
+func JobFilterFromQuery(q url.Values) JobFilter {
+	return JobFilter{
+		Remote:   q.Get("remote") == "1",
+		Unseen:   q.Get("unseen") == "1",
+		Location: strings.TrimSpace(q.Get("location")),
+		Keyword:  strings.TrimSpace(q.Get("keyword")),
+	}
+}
+
+// IsEmpty returns true if the filter does not exclude any jobs.
+func (f JobFilter) IsEmpty() bool {
+	return f == JobFilter{}
+}
+
+// Query returns the filter encoded as url query params, so it can be carried
+// through navigation links.
+func (f JobFilter) Query() string {
+	q := url.Values{}
+	if f.Remote {
+		q.Set("remote", "1")
+	}
+	if f.Unseen {
+		q.Set("unseen", "1")
+	}
+	if f.Location != "" {
+		q.Set("location", f.Location)
+	}
+	if f.Keyword != "" {
+		q.Set("keyword", f.Keyword)
+	}
+	return q.Encode()
+}
+
+// whereClause returns the sql conditions and their args for a query on the
+// hiring_job table. Every condition is prefixed with "and" so the result can
+// be appended to an existing WHERE clause. Values are always passed as args.
+func (f JobFilter) whereClause() (string, []any) {
+	var sb strings.Builder
+	var args []any
+
+	if f.Remote {
+		sb.WriteString(" and hiring_job.remote=1")
+	}
+
+	if f.Unseen {
+		sb.WriteString(" and hiring_job.seen=0")
+	}
+
+	if f.Location != "" {
+		sb.WriteString(` and EXISTS (
+              SELECT 1 FROM hiring_job_location
+              WHERE hiring_job_location.hiring_job_hn_id=hiring_job.hn_id
+                and hiring_job_location.location LIKE ? ESCAPE '\')`)
+		args = append(args, "%"+escapeLike(f.Location)+"%")
+	}
+
+	if match := ftsQuery(f.Keyword); match != "" {
+		sb.WriteString(" and hiring_job.id IN (SELECT rowid FROM hiring_job_fts WHERE hiring_job_fts MATCH ?)")
+		args = append(args, match)
+	}
+
+	return sb.String(), args
+}
+
+// escapeLike escapes the LIKE wildcards in s.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
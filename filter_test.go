@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestJobFilter_Query(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   JobFilter
+		expected string
+	}{
+		{name: "empty", filter: JobFilter{}, expected: ""},
+		{
+			name:     "all_fields",
+			filter:   JobFilter{Remote: true, Unseen: true, Location: "new york", Keyword: "c++"},
+			expected: "keyword=c%2B%2B&location=new+york&remote=1&unseen=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := tt.filter.Query()
+			if res != tt.expected {
+				t.Fatalf("expected %q, got %q", tt.expected, res)
+			}
+
+			q, err := url.ParseQuery(res)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+			if f := JobFilterFromQuery(q); f != tt.filter {
+				t.Fatalf("expected filter %+v, got %+v", tt.filter, f)
+			}
+		})
+	}
+}
+
+func TestJobFilter_whereClause(t *testing.T) {
+	f := JobFilter{Location: `50%_off\`, Keyword: "go"}
+	_, args := f.whereClause()
+
+	expected := []any{`%50\%\_off\\%`, `"go"`}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("expected args %v, got %v", expected, args)
+	}
+
+	if where, args := (JobFilter{}).whereClause(); where != "" || args != nil {
+		t.Fatalf("expected empty where clause, got %q %v", where, args)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !sqlite_fts5 && !fts5
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain fails right away when the tests are built without FTS5, instead
+// of every test that opens a database failing with ErrNoFts5.
+func TestMain(m *testing.M) {
+	fmt.Fprintln(os.Stderr, `the tests need SQLite FTS5, run them with "make test" or "go test -tags sqlite_fts5"`)
+	os.Exit(1)
+}
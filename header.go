@@ -0,0 +1,286 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JobHeader is the structured data found in the first line of a job post.
+// Most posts follow the "Company | Role | Location | REMOTE | Salary"
+// convention, but the order and number of fields varies.
+type JobHeader struct {
+	Company        string
+	Roles          []string
+	Locations      []string
+	Remote         bool
+	Onsite         bool
+	Hybrid         bool
+	Visa           bool
+	SalaryMin      uint64
+	SalaryMax      uint64
+	SalaryCurrency string
+}
+
+var (
+	headerSeparatorRegexp     = regexp.MustCompile(`\s*[|•｜]+\s*`)
+	headerDashSeparatorRegexp = regexp.MustCompile(`\s+[-–—]\s+`)
+	headerURLRegexp           = regexp.MustCompile(`(?i)^(https?://|www\.)\S*$|^[\w-]+(\.[\w-]+)*\.(com|io|co|ai|dev|org|net|app|tech|so|xyz|de|uk)(/\S*)?$`)
+	headerURLParensRegexp     = regexp.MustCompile(`(?i)\s*\(\s*(https?://|www\.)[^)]*\)|\s*(https?://|www\.)\S+`)
+	// companyQualifierRegexp matches a trailing batch or qualifier of the
+	// company, like "(YC W21)" in "Foo (YC W21)".
+	companyQualifierRegexp = regexp.MustCompile(`\s*\([^()]*\)$`)
+
+	remoteRegexp = regexp.MustCompile(`(?i)\bremote(ly)?\b`)
+	onsiteRegexp = regexp.MustCompile(`(?i)\bon[- ]?site\b|\bin[- ](office|person)\b`)
+	hybridRegexp = regexp.MustCompile(`(?i)\bhybrid\b`)
+
+	visaRegexp         = regexp.MustCompile(`(?i)\bvisas?\b`)
+	noVisaRegexp       = regexp.MustCompile(`(?i)\bno\b.*\b(visas?|sponsorship)\b|\b(not|unable to|cannot|can't|can’t|don't|do not)\b.*\bsponsor|\bwithout\b.*\bvisas?\b`)
+	employmentRegexp   = regexp.MustCompile(`(?i)\b(full[- ]?time|part[- ]?time|contract(or|ors)?|freelance|permanent|ft|pt|equity|fte|w2|1099)\b`)
+	nonWordRegexp      = regexp.MustCompile(`[\s\W]+`)
+	roleKeywordsRegexp = regexp.MustCompile(`(?i)\b(engineers?|engineering|developers?|devs?|designers?|managers?|scientists?|leads?|architects?|sres?|devops|analysts?|head|directors?|cto|vp|interns?|internships?|researchers?|programmers?|consultants?|administrators?|specialists?|recruiters?|marketing|sales|founding|staff|principal|swe|qa|testers?|writers?|product|data|full[- ]?stack|front[- ]?end|back[- ]?end|ios|android|mobile|security|infrastructure|platform|roles?|positions?)\b`)
+
+	// salaryRegexp matches amounts like "$120k - $150k", "€70-90k",
+	// "£60,000 to £80,000" or "USD 100k".
+	salaryRegexp = regexp.MustCompile(
+		`(?i)(?:\b(usd|eur|gbp|cad|aud|chf)\s*)?([$€£])?\s*(\d{1,3}(?:,\d{3})+|\d+(?:\.\d+)?)\s*(k\b)?` +
+			`(?:\s*(?:-|–|—|to)\s*(?:usd|eur|gbp|cad|aud|chf)?\s*[$€£]?\s*(\d{1,3}(?:,\d{3})+|\d+(?:\.\d+)?)\s*(k\b)?)?` +
+			`(?:\s*\b(usd|eur|gbp|cad|aud|chf)\b)?`,
+	)
+
+	compensationRegexp = regexp.MustCompile(`[$€£]\s*\d`)
+
+	locationSeparatorRegexp = regexp.MustCompile(`(?i)\s*(?:/|;|&|\(|\)|\bor\b|\band\b)\s*`)
+	locationStopWordsRegexp = regexp.MustCompile(`(?i)^(in|only|ok|friendly|first|preferred|possible|optional|partially|fully|flexible|timezones?|tz)$`)
+
+	// regionCodeRegexp matches US state and Canadian province codes, and
+	// countryRegexp the countries and regions that qualify a city, like
+	// "CA" in "San Francisco, CA" or "Germany" in "Berlin, Germany".
+	regionCodeRegexp = regexp.MustCompile(`^(A[KLRZ]|C[AOT]|D[CE]|FL|GA|HI|I[ADLN]|K[SY]|LA|M[ADEINOST]|N[CDEHJMVY]|O[HKR]|PA|RI|S[CD]|T[NX]|UT|V[AT]|W[AIVY]|BC|AB|SK|MB|ON|QC|NS|NL|PE)$`)
+	countryRegexp    = regexp.MustCompile(`(?i)^(usa?|uk|eu|uae|united states|united kingdom|england|scotland|ireland|germany|france|spain|portugal|italy|netherlands|belgium|switzerland|austria|poland|czechia|czech republic|sweden|norway|denmark|finland|estonia|canada|mexico|brazil|argentina|colombia|australia|new zealand|india|singapore|japan|israel)$`)
+
+	// techKeywordsRegexp matches segments that name the tech stack, like
+	// "Rust" or "Go, React", and not a location.
+	techKeywordsRegexp = regexp.MustCompile(`(?i)^(go|golang|rust|python|java|javascript|typescript|ruby|rails|elixir|erlang|haskell|scala|clojure|kotlin|swift|c|c\+\+|c#|\.net|php|perl|react|vue|angular|node(\.?js)?|django|kubernetes|k8s|aws|gcp|azure|sql|postgres(ql)?|llms?|ml|ai)$`)
+)
+
+var currencySymbols = map[string]string{"$": "USD", "€": "EUR", "£": "GBP"}
+
+// ParseJobHeader extracts a JobHeader from the first line of a job post text.
+func ParseJobHeader(text string) *JobHeader {
+	h := &JobHeader{}
+
+	line := headerLine(text)
+	if line == "" {
+		return h
+	}
+
+	segments := headerSeparatorRegexp.Split(line, -1)
+	if len(segments) == 1 {
+		segments = headerDashSeparatorRegexp.Split(line, -1)
+	}
+
+	h.Company = strings.Trim(headerURLParensRegexp.ReplaceAllString(segments[0], ""), " ,:-")
+	if company := strings.Trim(companyQualifierRegexp.ReplaceAllString(h.Company, ""), " ,:-"); company != "" {
+		h.Company = company
+	}
+
+	for _, seg := range segments[1:] {
+		seg = strings.TrimSpace(seg)
+		if seg == "" || headerURLRegexp.MatchString(seg) {
+			continue
+		}
+
+		if visaRegexp.MatchString(seg) {
+			h.Visa = !noVisaRegexp.MatchString(seg)
+			continue
+		}
+
+		// segments with amounts that are not a yearly salary, like hourly
+		// rates, are still compensation and not a location.
+		if h.parseSalary(seg) || compensationRegexp.MatchString(seg) {
+			continue
+		}
+
+		if h.parseWorkMode(seg) {
+			h.Locations = append(h.Locations, splitLocations(seg)...)
+			continue
+		}
+
+		if isEmploymentType(seg) {
+			continue
+		}
+
+		if roleKeywordsRegexp.MatchString(seg) {
+			for role := range strings.SplitSeq(seg, ",") {
+				if role = strings.TrimSpace(role); role != "" {
+					h.Roles = append(h.Roles, role)
+				}
+			}
+			continue
+		}
+
+		if isTechStack(seg) {
+			continue
+		}
+
+		h.Locations = append(h.Locations, splitLocations(seg)...)
+	}
+
+	return h
+}
+
+// headerLine returns the first line of a job post as plain text.
+func headerLine(text string) string {
+	line := strings.TrimSpace(text)
+	if i := strings.Index(line, "<p>"); i >= 0 {
+		line = line[:i]
+	}
+	if i := strings.Index(line, "\n"); i >= 0 {
+		line = line[:i]
+	}
+
+	line = htmlTagRegexp.ReplaceAllString(line, "")
+	return strings.TrimSpace(html.UnescapeString(line))
+}
+
+// parseWorkMode sets the remote, onsite and hybrid flags found in seg and
+// reports whether any were found.
+func (h *JobHeader) parseWorkMode(seg string) bool {
+	found := false
+	if remoteRegexp.MatchString(seg) {
+		h.Remote, found = true, true
+	}
+	if onsiteRegexp.MatchString(seg) {
+		h.Onsite, found = true, true
+	}
+	if hybridRegexp.MatchString(seg) {
+		h.Hybrid, found = true, true
+	}
+	return found
+}
+
+// parseSalary sets the salary range found in seg and reports whether one was
+// found. Numbers without a currency or "k" suffix, and yearly amounts below
+// 1000 (hourly rates, "$50M raised"), are ignored.
+func (h *JobHeader) parseSalary(seg string) bool {
+	for _, m := range salaryRegexp.FindAllStringSubmatch(seg, -1) {
+		preCode, symbol, minStr, minK, maxStr, maxK, postCode := m[1], m[2], m[3], m[4], m[5], m[6], m[7]
+		if preCode == "" && symbol == "" && postCode == "" && minK == "" && maxK == "" {
+			continue
+		}
+
+		minVal := parseSalaryAmount(minStr, minK != "" || (maxK != "" && !strings.Contains(minStr, ",")))
+		maxVal := minVal
+		if maxStr != "" {
+			maxVal = parseSalaryAmount(maxStr, maxK != "")
+		}
+		if minVal < 1000 || maxVal < minVal {
+			continue
+		}
+
+		currency := strings.ToUpper(preCode + postCode)
+		if currency == "" {
+			currency = currencySymbols[symbol]
+		}
+
+		h.SalaryMin, h.SalaryMax, h.SalaryCurrency = minVal, maxVal, currency
+		return true
+	}
+
+	return false
+}
+
+// parseSalaryAmount converts "120", "120.5" or "120,000" into a whole amount,
+// multiplying by 1000 when thousands is true.
+func parseSalaryAmount(s string, thousands bool) uint64 {
+	val, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	if thousands {
+		val *= 1000
+	}
+	return uint64(val)
+}
+
+// isEmploymentType reports whether seg only describes the type of employment,
+// e.g. "Full-time" or "Contract & Full Time".
+func isEmploymentType(seg string) bool {
+	if !employmentRegexp.MatchString(seg) {
+		return false
+	}
+	rest := employmentRegexp.ReplaceAllString(seg, "")
+	rest = strings.TrimSpace(nonWordRegexp.ReplaceAllString(rest, " "))
+	return rest == "" || strings.EqualFold(rest, "or") || strings.EqualFold(rest, "and")
+}
+
+// isTechStack reports whether seg only lists tech keywords, e.g. "Rust" or
+// "Go, React".
+func isTechStack(seg string) bool {
+	for part := range strings.SplitSeq(locationSeparatorRegexp.ReplaceAllString(seg, ","), ",") {
+		if part = strings.TrimSpace(part); part != "" && !techKeywordsRegexp.MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLocations returns the locations in seg after removing work modes.
+// Commas split locations unless the part after them qualifies the city
+// before, so "San Francisco, CA" and "Berlin, Germany" stay single locations.
+func splitLocations(seg string) []string {
+	seg = remoteRegexp.ReplaceAllString(seg, "/")
+	seg = onsiteRegexp.ReplaceAllString(seg, "/")
+	seg = hybridRegexp.ReplaceAllString(seg, "/")
+
+	var locations []string
+	for part := range strings.SplitSeq(locationSeparatorRegexp.ReplaceAllString(seg, "/"), "/") {
+		for _, loc := range splitLocationCommas(part) {
+			if loc = cleanLocation(loc); loc != "" {
+				locations = append(locations, loc)
+			}
+		}
+	}
+
+	return locations
+}
+
+// splitLocationCommas splits s on commas, joining region and country
+// qualifiers onto the city before them.
+func splitLocationCommas(s string) []string {
+	var res []string
+	for p := range strings.SplitSeq(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if len(res) > 0 && isRegionQualifier(p) && !isRegionQualifier(res[len(res)-1]) {
+			res[len(res)-1] = res[len(res)-1] + ", " + p
+			continue
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// isRegionQualifier reports whether s is a state, province, country or
+// region that can follow a city.
+func isRegionQualifier(s string) bool {
+	s = strings.Trim(s, " .")
+	return regionCodeRegexp.MatchString(s) || countryRegexp.MatchString(s)
+}
+
+// cleanLocation strips punctuation and filler words such as "in" or "only".
+func cleanLocation(loc string) string {
+	var words []string
+	for w := range strings.FieldsSeq(loc) {
+		if locationStopWordsRegexp.MatchString(strings.Trim(w, ",.:-–")) {
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Trim(strings.Join(words, " "), " ,.:-–")
+}
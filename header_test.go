@@ -0,0 +1,224 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJobHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected *JobHeader
+	}{
+		{
+			name: "conventional",
+			text: "Acme | Senior Go Engineer | Berlin, Germany | REMOTE | €70-90k<p>We build rockets.",
+			expected: &JobHeader{
+				Company:        "Acme",
+				Roles:          []string{"Senior Go Engineer"},
+				Locations:      []string{"Berlin, Germany"},
+				Remote:         true,
+				SalaryMin:      70000,
+				SalaryMax:      90000,
+				SalaryCurrency: "EUR",
+			},
+		},
+		{
+			name: "multiple_roles_and_us_state",
+			text: "Initech (https:&#x2F;&#x2F;initech.com) | Backend Engineer, Frontend Engineer, Designer | San Francisco, CA | ONSITE | $150k - $200k + equity",
+			expected: &JobHeader{
+				Company:        "Initech",
+				Roles:          []string{"Backend Engineer", "Frontend Engineer", "Designer"},
+				Locations:      []string{"San Francisco, CA"},
+				Onsite:         true,
+				SalaryMin:      150000,
+				SalaryMax:      200000,
+				SalaryCurrency: "USD",
+			},
+		},
+		{
+			name: "company_with_batch",
+			text: "Foo (YC W21) | Founding Engineer | Remote",
+			expected: &JobHeader{
+				Company: "Foo",
+				Roles:   []string{"Founding Engineer"},
+				Remote:  true,
+			},
+		},
+		{
+			name: "remote_with_region",
+			text: "Globex | Staff SRE | Remote (US/Canada) | Full-time",
+			expected: &JobHeader{
+				Company:   "Globex",
+				Roles:     []string{"Staff SRE"},
+				Locations: []string{"US", "Canada"},
+				Remote:    true,
+			},
+		},
+		{
+			name: "hybrid_location",
+			text: "Umbrella Corp | Data Scientist | London, UK (Hybrid) | £60,000 to £80,000 | Visa sponsorship",
+			expected: &JobHeader{
+				Company:        "Umbrella Corp",
+				Roles:          []string{"Data Scientist"},
+				Locations:      []string{"London, UK"},
+				Hybrid:         true,
+				Visa:           true,
+				SalaryMin:      60000,
+				SalaryMax:      80000,
+				SalaryCurrency: "GBP",
+			},
+		},
+		{
+			name: "no_visa",
+			text: "Hooli | Android Developer | NYC | Onsite | No visa sponsorship",
+			expected: &JobHeader{
+				Company:   "Hooli",
+				Roles:     []string{"Android Developer"},
+				Locations: []string{"NYC"},
+				Onsite:    true,
+			},
+		},
+		{
+			name: "onsite_or_remote",
+			text: "Vandelay Industries | Full Stack Engineer | Onsite in Austin or Remote | USD 120k-160k",
+			expected: &JobHeader{
+				Company:        "Vandelay Industries",
+				Roles:          []string{"Full Stack Engineer"},
+				Locations:      []string{"Austin"},
+				Remote:         true,
+				Onsite:         true,
+				SalaryMin:      120000,
+				SalaryMax:      160000,
+				SalaryCurrency: "USD",
+			},
+		},
+		{
+			name: "url_segment_and_lowercase",
+			text: "stark industries | founding engineer | remote-friendly | https://stark.example.com/jobs",
+			expected: &JobHeader{
+				Company: "stark industries",
+				Roles:   []string{"founding engineer"},
+				Remote:  true,
+			},
+		},
+		{
+			name: "bare_domain_segment",
+			text: "Wayne Enterprises | wayne.com | Security Engineer | Gotham",
+			expected: &JobHeader{
+				Company:   "Wayne Enterprises",
+				Roles:     []string{"Security Engineer"},
+				Locations: []string{"Gotham"},
+			},
+		},
+		{
+			name: "dash_separated",
+			text: "Cyberdyne - ML Researcher - Sunnyvale - $180,000",
+			expected: &JobHeader{
+				Company:        "Cyberdyne",
+				Roles:          []string{"ML Researcher"},
+				Locations:      []string{"Sunnyvale"},
+				SalaryMin:      180000,
+				SalaryMax:      180000,
+				SalaryCurrency: "USD",
+			},
+		},
+		{
+			name: "newline_header_and_html",
+			text: "<i>Soylent</i> | Product Manager &amp; Designer | Paris; Lyon\nMore text | not header",
+			expected: &JobHeader{
+				Company:   "Soylent",
+				Roles:     []string{"Product Manager & Designer"},
+				Locations: []string{"Paris", "Lyon"},
+			},
+		},
+		{
+			name: "ignores_non_salary_numbers",
+			text: "Tyrell | 10 Engineers | Remote OK | $80/hr",
+			expected: &JobHeader{
+				Company: "Tyrell",
+				Roles:   []string{"10 Engineers"},
+				Remote:  true,
+			},
+		},
+		{
+			name: "salary_with_k_suffix_only",
+			text: "Oscorp | iOS Engineer | Remote (EU timezones) | 90k-120k EUR | Contract or Full-time",
+			expected: &JobHeader{
+				Company:        "Oscorp",
+				Roles:          []string{"iOS Engineer"},
+				Locations:      []string{"EU"},
+				Remote:         true,
+				SalaryMin:      90000,
+				SalaryMax:      120000,
+				SalaryCurrency: "EUR",
+			},
+		},
+		{
+			name: "in_office",
+			text: "Pied Piper | Platform Engineer | Palo Alto (in-office)",
+			expected: &JobHeader{
+				Company:   "Pied Piper",
+				Roles:     []string{"Platform Engineer"},
+				Locations: []string{"Palo Alto"},
+				Onsite:    true,
+			},
+		},
+		{
+			name: "comma_separated_cities",
+			text: "Initrode | Backend Engineer | SF, NYC, Austin, TX, USA | Onsite",
+			expected: &JobHeader{
+				Company:   "Initrode",
+				Roles:     []string{"Backend Engineer"},
+				Locations: []string{"SF", "NYC", "Austin, TX, USA"},
+				Onsite:    true,
+			},
+		},
+		{
+			name: "comma_separated_countries",
+			text: "Globex | Staff SRE | Remote (US, Canada)",
+			expected: &JobHeader{
+				Company:   "Globex",
+				Roles:     []string{"Staff SRE"},
+				Locations: []string{"US", "Canada"},
+				Remote:    true,
+			},
+		},
+		{
+			name: "tech_stack_segment",
+			text: "Baz | Rust | Remote",
+			expected: &JobHeader{
+				Company: "Baz",
+				Remote:  true,
+			},
+		},
+		{
+			name: "tech_stack_list",
+			text: "Baz | Go, React, Postgres | Lisbon, Portugal",
+			expected: &JobHeader{
+				Company:   "Baz",
+				Locations: []string{"Lisbon, Portugal"},
+			},
+		},
+		{
+			name:     "no_separators",
+			text:     "We are hiring engineers to build the future of payments.",
+			expected: &JobHeader{Company: "We are hiring engineers to build the future of payments."},
+		},
+		{
+			name:     "empty",
+			text:     "",
+			expected: &JobHeader{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ParseJobHeader(tt.text)
+			if !reflect.DeepEqual(res, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, res)
+			}
+		})
+	}
+}
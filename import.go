@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// maxImportLine is the longest JSON Lines record importJSONL accepts.
+const maxImportLine = 16 << 20
+
+// importOutcome is what importing a job did to the store.
+type importOutcome int
+
+const (
+	importUnchanged importOutcome = iota
+	importCreated
+	importUpdated
+)
+
+// ImportResult counts what an import did.
+type ImportResult struct {
+	Stories   uint64
+	Created   uint64
+	Updated   uint64
+	Unchanged uint64
+}
+
+func (r ImportResult) String() string {
+	return fmt.Sprintf("%d jobs created, %d updated, %d unchanged, %d new stories",
+		r.Created, r.Updated, r.Unchanged, r.Stories)
+}
+
+// importJSONL loads the jobs of a JSON Lines export into store. Jobs missing
+// from the store are created along with their story. Jobs already stored
+// keep their text and status, and their triage state is merged: they become
+// seen or saved if they are in the export, and get its notes if they have
+// none. Importing the same file twice doesn't change anything.
+//
+// Every job is imported on its own, so an import failing on an invalid line
+// keeps the jobs before it and can be run again once the file is fixed.
+func importJSONL(store *HNStore, r io.Reader) (ImportResult, error) {
+	var res ImportResult
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxImportLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var j ExportJob
+		if err := json.Unmarshal(scanner.Bytes(), &j); err != nil {
+			return res, fmt.Errorf("line %d: failed to decode job: %w", line, err)
+		}
+		if j.HnId == 0 || j.StoryHnId == 0 {
+			return res, fmt.Errorf("line %d: job is missing hn_id or story_hn_id", line)
+		}
+
+		storyCreated, outcome, err := store.ImportJob(j)
+		if err != nil {
+			return res, fmt.Errorf("line %d: %w", line, err)
+		}
+		if storyCreated {
+			res.Stories++
+		}
+
+		switch outcome {
+		case importCreated:
+			res.Created++
+			if err := store.SaveJobHeader(j.HnId, ParseJobHeader(j.Text)); err != nil {
+				log.Printf("failed to save job header %d: %v", j.HnId, err)
+			}
+		case importUpdated:
+			res.Updated++
+		default:
+			res.Unchanged++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return res, fmt.Errorf("line %d: job is longer than %d bytes", line+1, maxImportLine)
+		}
+		return res, fmt.Errorf("failed to read import file: %w", err)
+	}
+
+	return res, nil
+}
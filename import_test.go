@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestImportJSONL(t *testing.T) {
+	srcDB := setupTestDB(t)
+	defer srcDB.Close()
+
+	src := &HNStore{db: srcDB}
+	story, job := setUpStoryWithJob(t, src)
+	if err := src.CreateJob(&HnJob{HnId: 2, Text: "Acme | Go | Remote", Time: 200, Status: jobStatusOk}, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+	if err := src.SetJobSaved(2); err != nil {
+		t.Fatalf("SetJobSaved() failed: %v", err)
+	}
+	notes := "call on monday"
+	if err := src.UpdateJob(2, JobPatch{Notes: &notes}); err != nil {
+		t.Fatalf("UpdateJob() failed: %v", err)
+	}
+	if err := src.SetJobAsSeen(job.HnId); err != nil {
+		t.Fatalf("SetJobAsSeen() failed: %v", err)
+	}
+
+	jobs, err := src.GetExportJobs(story.HnId, ExportFilter{})
+	if err != nil {
+		t.Fatalf("GetExportJobs() failed: %v", err)
+	}
+	var export bytes.Buffer
+	if err := exportJSONL(&export, story, jobs); err != nil {
+		t.Fatalf("exportJSONL() failed: %v", err)
+	}
+
+	dstDB := setupTestDB(t)
+	defer dstDB.Close()
+	dst := &HNStore{db: dstDB}
+
+	t.Run("creates_story_and_jobs", func(t *testing.T) {
+		res, err := importJSONL(dst, bytes.NewReader(export.Bytes()))
+		if err != nil {
+			t.Fatalf("importJSONL() failed: %v", err)
+		}
+		if expected := (ImportResult{Stories: 1, Created: 2}); res != expected {
+			t.Fatalf("expected result %+v, got %+v", expected, res)
+		}
+
+		imported, err := dst.GetStory(story.HnId)
+		if err != nil {
+			t.Fatalf("GetStory() failed: %v", err)
+		}
+		if *imported != *story {
+			t.Errorf("expected story %+v, got %+v", story, imported)
+		}
+
+		saved, err := dst.GetJob(2)
+		if err != nil {
+			t.Fatalf("GetJob() failed: %v", err)
+		}
+		if saved.Saved != 1 || saved.Seen != 0 || saved.Notes != notes || saved.Text != "Acme | Go | Remote" {
+			t.Errorf("expected saved job with notes, got %+v", saved)
+		}
+		if seen := queryTestJobById(t, dst, job.HnId); seen.Seen != 1 {
+			t.Errorf("expected job %d to be seen", job.HnId)
+		}
+
+		found, err := dst.GetJobsPage(story.HnId, 0, 10, JobFilter{Remote: true, Keyword: "acme"})
+		if err != nil {
+			t.Fatalf("GetJobsPage() failed: %v", err)
+		}
+		if len(found) != 1 || found[0].HnId != 2 {
+			t.Errorf("expected imported job to be searchable by header and text, got %+v", found)
+		}
+	})
+
+	t.Run("is_idempotent", func(t *testing.T) {
+		res, err := importJSONL(dst, bytes.NewReader(export.Bytes()))
+		if err != nil {
+			t.Fatalf("importJSONL() failed: %v", err)
+		}
+		if expected := (ImportResult{Unchanged: 2}); res != expected {
+			t.Fatalf("expected result %+v, got %+v", expected, res)
+		}
+	})
+
+	t.Run("merges_state", func(t *testing.T) {
+		mine := "already applied"
+		if err := dst.UpdateJob(job.HnId, JobPatch{Notes: &mine}); err != nil {
+			t.Fatalf("UpdateJob() failed: %v", err)
+		}
+		if err := dst.UnsetJobSaved(2); err != nil {
+			t.Fatalf("UnsetJobSaved() failed: %v", err)
+		}
+
+		lines := strings.Join([]string{
+			`{"hn_id":1,"story_hn_id":1,"text":"changed text","time":1,"status":2,"seen":false,"saved":true,"notes":"theirs"}`,
+			`{"hn_id":2,"story_hn_id":1,"text":"Acme | Go | Remote","time":200,"status":1,"seen":false,"saved":true}`,
+		}, "\n")
+		res, err := importJSONL(dst, strings.NewReader(lines))
+		if err != nil {
+			t.Fatalf("importJSONL() failed: %v", err)
+		}
+		if expected := (ImportResult{Updated: 2}); res != expected {
+			t.Fatalf("expected result %+v, got %+v", expected, res)
+		}
+
+		merged, err := dst.GetJob(job.HnId)
+		if err != nil {
+			t.Fatalf("GetJob() failed: %v", err)
+		}
+		if merged.Seen != 1 || merged.Saved != 1 || merged.Notes != mine {
+			t.Errorf("expected seen and saved job keeping its notes, got %+v", merged)
+		}
+		if merged.Text != job.Text || merged.Status != jobStatusOk {
+			t.Errorf("expected job to keep its text and status, got %+v", merged)
+		}
+		if resaved := queryTestJobById(t, dst, 2); resaved.Saved != 1 {
+			t.Errorf("expected job 2 to be saved again")
+		}
+	})
+}
+
+func TestImportJSONL_invalidLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{name: "invalid_json", input: "\n{\"hn_id\":", expectedErr: "line 2: failed to decode job"},
+		{name: "missing_ids", input: `{"hn_id":5}`, expectedErr: "line 1: job is missing hn_id or story_hn_id"},
+		{name: "unknown_story", input: `{"hn_id":5,"story_hn_id":9,"status":1}`, expectedErr: "story 9 of job 5 is not stored and has no title"},
+		{name: "unknown_status", input: "\n\n" + `{"hn_id":5,"story_hn_id":9,"story_title":"t","status":7}`, expectedErr: `line 3: job 5 has an unknown status 7`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			_, err := importJSONL(&HNStore{db: db}, strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"html"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// allowedJobTags are the tags kept by hnTokens, the subset of html Hacker
+// News uses in posts. Any other tag is dropped, keeping its text.
+var allowedJobTags = map[string]bool{
+	"p":    true,
+	"a":    true,
+	"i":    true,
+	"pre":  true,
+	"code": true,
+}
+
+// droppedContentTags are removed along with everything up to their end tag.
+var droppedContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"textarea": true,
+	"title":    true,
+}
+
+type hnTokenKind int
+
+const (
+	hnText hnTokenKind = iota
+	hnStartTag
+	hnEndTag
+)
+
+// hnToken is a token of the html of a job text.
+type hnToken struct {
+	kind hnTokenKind
+	// text is the unescaped text of hnText tokens.
+	text string
+	// tag is the lowercase name of hnStartTag and hnEndTag tokens.
+	tag string
+	// href is the safe link of "a" start tags, empty if the link is unsafe.
+	href string
+}
+
+// hnTokens splits the html of a job text into tokens. Only the tags in
+// allowedJobTags are returned, without attributes except the href of links,
+// which must be an http or https URL. Other tags are dropped, keeping their
+// text, except droppedContentTags, which are dropped along with their content.
+// Comments are dropped too, and entities in text are unescaped.
+func hnTokens(s string) iter.Seq[hnToken] {
+	return func(yield func(hnToken) bool) {
+		var text strings.Builder
+		flush := func() bool {
+			if text.Len() == 0 {
+				return true
+			}
+			t := hnToken{kind: hnText, text: html.UnescapeString(text.String())}
+			text.Reset()
+			return yield(t)
+		}
+
+		for len(s) > 0 {
+			start := strings.IndexByte(s, '<')
+			if start < 0 {
+				text.WriteString(s)
+				break
+			}
+			text.WriteString(s[:start])
+			s = s[start:]
+
+			if strings.HasPrefix(s, "<!--") {
+				end := strings.Index(s, "-->")
+				if end < 0 {
+					s = ""
+					break
+				}
+				s = s[end+len("-->"):]
+				continue
+			}
+
+			t, n, ok := parseTag(s)
+			if !ok {
+				text.WriteByte('<')
+				s = s[1:]
+				continue
+			}
+			s = s[n:]
+
+			if droppedContentTags[t.name] && !t.end {
+				end := strings.Index(strings.ToLower(s), "</"+t.name)
+				if end < 0 {
+					s = ""
+					break
+				}
+				s = s[end:]
+				continue
+			}
+			if !allowedJobTags[t.name] {
+				continue
+			}
+
+			if !flush() {
+				return
+			}
+			tok := hnToken{kind: hnStartTag, tag: t.name}
+			if t.end {
+				tok.kind = hnEndTag
+			} else if t.name == "a" {
+				tok.href, _ = safeHref(t.attrs["href"])
+			}
+			if !yield(tok) {
+				return
+			}
+		}
+
+		flush()
+	}
+}
+
+// safeHref returns the unescaped value of an href attribute if it is an
+// absolute http or https URL.
+func safeHref(value string) (string, bool) {
+	href := strings.TrimSpace(html.UnescapeString(value))
+	if strings.ContainsFunc(href, func(r rune) bool { return r < ' ' || r == 0x7f }) {
+		return "", false
+	}
+	u, err := url.Parse(href)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return "", false
+	}
+	return u.String(), true
+}
+
+type htmlTag struct {
+	name  string
+	end   bool
+	attrs map[string]string
+}
+
+// parseTag parses the tag at the start of s, returning it along with its
+// length. ok is false if s doesn't start with a complete tag.
+func parseTag(s string) (t htmlTag, n int, ok bool) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		t.end = true
+		i++
+	}
+	nameStart := i
+	for i < len(s) && isTagNameChar(s[i], i == nameStart) {
+		i++
+	}
+	if i == nameStart {
+		return htmlTag{}, 0, false
+	}
+	t.name = strings.ToLower(s[nameStart:i])
+	t.attrs = map[string]string{}
+
+	for {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+		if i >= len(s) {
+			return htmlTag{}, 0, false
+		}
+		if s[i] == '>' {
+			return t, i + 1, true
+		}
+
+		attrStart := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(s[attrStart:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) || s[i] != '=' {
+			if _, ok := t.attrs[name]; !ok {
+				t.attrs[name] = ""
+			}
+			continue
+		}
+		i++
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return htmlTag{}, 0, false
+		}
+
+		var value string
+		if q := s[i]; q == '"' || q == '\'' {
+			end := strings.IndexByte(s[i+1:], q)
+			if end < 0 {
+				return htmlTag{}, 0, false
+			}
+			value = s[i+1 : i+1+end]
+			i += end + 2
+		} else {
+			valueStart := i
+			for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+				i++
+			}
+			value = s[valueStart:i]
+		}
+		// Like browsers, only the first of duplicated attributes counts.
+		if _, ok := t.attrs[name]; !ok {
+			t.attrs[name] = value
+		}
+	}
+}
+
+func isTagNameChar(c byte, first bool) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+		return true
+	}
+	return !first && '0' <= c && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
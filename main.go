@@ -1,53 +1,291 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// Exit codes returned by run.
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
 func main() {
-	sync := flag.Bool("sync", false, "Sync who is hiring data")
-	serve := flag.Bool("serve", false, "Run server")
-	verify := flag.Bool("verify", false, "Verify saved jobs are still OK")
-	flag.Parse()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the subcommand named by the first arg and returns the exit
+// code. Args starting with "-" are handled as the deprecated boolean flags.
+// Commands stop early when ctx is cancelled.
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") && !isHelpArg(args[0]) {
+		return runLegacy(ctx, args, stdout, stderr)
+	}
+
+	if len(args) == 0 || isHelpArg(args[0]) || args[0] == "help" {
+		printUsage(stderr)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOk
+	}
+
+	cmd, ok := commands()[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	return cmd.execute(ctx, args[1:], stdout, stderr)
+}
+
+func isHelpArg(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
 
-	db, err := sqlx.Open("sqlite3", "whoishiring.db")
+// printUsage prints the list of available commands.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: whoishiring <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	cmds := commands()
+	for _, name := range commandNames {
+		fmt.Fprintf(w, "  %-17s %s\n", name, cmds[name].summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "whoishiring <command> -h" for the flags of a command.`)
+}
+
+// usageError is returned by a command when its args are invalid.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+// commandEnv is what a command needs to run.
+type commandEnv struct {
+	ctx    context.Context
+	cfg    *Config
+	store  *HNStore
+	db     *sqlx.DB
+	args   []string
+	stdout io.Writer
+}
+
+// command is a whoishiring subcommand with its own flag set.
+type command struct {
+	fs      *flag.FlagSet
+	summary string
+	args    string
+	// skipMigrate leaves the database schema untouched before run.
+	skipMigrate bool
+	run         func(env *commandEnv) error
+}
+
+// execute parses args, opens the store and runs the command.
+func (c *command) execute(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	c.fs.SetOutput(stderr)
+	addConfigFlags(c.fs)
+	c.fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: whoishiring %s [flags] %s\n\n%s\n\nFlags:\n", c.fs.Name(), c.args, c.summary)
+		c.fs.PrintDefaults()
+	}
+
+	if err := c.fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOk
+		}
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(c.fs, os.Getenv)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	db, err := openDB(ctx, cfg, !c.skipMigrate)
 	if err != nil {
-		log.Fatalf("failed to open database: %v", err)
+		fmt.Fprintln(stderr, err)
+		return exitError
 	}
 	defer db.Close()
 
+	env := &commandEnv{
+		ctx:    ctx,
+		cfg:    cfg,
+		store:  NewHNStore(db),
+		db:     db,
+		args:   c.fs.Args(),
+		stdout: stdout,
+	}
+	if err := c.run(env); err != nil {
+		fmt.Fprintln(stderr, err)
+		var uerr usageError
+		if errors.As(err, &uerr) {
+			c.fs.Usage()
+			return exitUsage
+		}
+		return exitError
+	}
+
+	return exitOk
+}
+
+// openDB opens the configured database and, if migrate is true, applies any
+// pending migrations.
+func openDB(ctx context.Context, cfg *Config, migrate bool) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite3", cfg.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
 	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping database: %v", err)
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if !migrate {
+		return db, nil
+	}
+
+	migrations, err := migrateUp(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	for _, m := range migrations {
+		log.Printf("applied migration %s", m.Source.Path)
+	}
+
+	return db, nil
+}
+
+// runLegacy supports the boolean flags used before subcommands existed. The
+// selected modes run in the same order as they always have. Without any of
+// them, the first arg left after the flags is run as a subcommand, so config
+// flags can also come before the command.
+func runLegacy(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("whoishiring", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	sync := fs.Bool("sync", false, "Deprecated: use the sync command")
+	serve := fs.Bool("serve", false, "Deprecated: use the serve command")
+	verify := fs.Bool("verify", false, "Deprecated: use the verify command")
+	backfillHeaders := fs.Bool("backfill-headers", false, "Deprecated: use the backfill-headers command")
+	search := fs.String("search", "", "Deprecated: use the search command")
+	migrateOnly := fs.Bool("migrate-only", false, "Deprecated: use the migrate command")
+	migrateStatus := fs.Bool("migrate-status", false, "Deprecated: use the migrate status command")
+	addConfigFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
 	}
 
-	store := NewHNStore(db)
-	baseUrl := "https://hacker-news.firebaseio.com/v0"
+	replacements := map[string]string{
+		"sync":             "sync",
+		"serve":            "serve",
+		"verify":           "verify",
+		"backfill-headers": "backfill-headers",
+		"search":           "search <query>",
+		"migrate-only":     "migrate",
+		"migrate-status":   "migrate status",
+	}
+	modeSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if r, ok := replacements[f.Name]; ok {
+			modeSet = true
+			fmt.Fprintf(stderr, "warning: -%s is deprecated, use \"whoishiring %s\" instead\n", f.Name, r)
+		}
+	})
 
-	if *sync {
-		client := NewClient(baseUrl)
-		sp := NewSyncProcess(store, client)
-		if err := sp.Run(); err != nil {
-			log.Fatal(err)
+	if fs.NArg() > 0 {
+		if modeSet {
+			fmt.Fprintf(stderr, "unexpected argument %q\n", fs.Arg(0))
+			return exitUsage
 		}
+		cmd, ok := commands()[fs.Arg(0)]
+		if !ok {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", fs.Arg(0))
+			printUsage(stderr)
+			return exitUsage
+		}
+
+		// Only config flags are set here, pass them on to the command.
+		var cmdArgs []string
+		fs.Visit(func(f *flag.Flag) {
+			cmdArgs = append(cmdArgs, "-"+f.Name+"="+f.Value.String())
+		})
+		return cmd.execute(ctx, append(cmdArgs, fs.Args()[1:]...), stdout, stderr)
+	}
+	if !modeSet {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(fs, os.Getenv)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
 	}
 
-	if *verify {
-		client := NewClient(baseUrl)
-		v := NewVerifyProcess(store, client)
-		if err := v.Run(); err != nil {
-			log.Fatal(err)
+	db, err := openDB(ctx, cfg, !*migrateStatus)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer db.Close()
+
+	if *migrateStatus {
+		if err := printMigrationStatus(ctx, db, stdout); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
 		}
+		return exitOk
+	}
+	if *migrateOnly {
+		return exitOk
 	}
 
-	if *serve {
-		server, err := InitializeNewServer(store)
-		if err != nil {
-			log.Fatal(err)
+	env := &commandEnv{ctx: ctx, cfg: cfg, store: NewHNStore(db), db: db, stdout: stdout}
+	steps := []struct {
+		enabled bool
+		run     func() error
+	}{
+		{*sync, func() error { return runSync(env, defaultFetchOptions(), defaultStoryWindow) }},
+		{*verify, func() error { return runVerify(env, defaultFetchOptions(), VerifyTarget{}) }},
+		{*backfillHeaders, func() error { return runBackfillHeaders(env) }},
+		{*search != "", func() error { return printSearchResults(env.store, stdout, *search, 0, 20) }},
+		{*serve, func() error { return runServe(env, &serveOptions{fetch: defaultFetchOptions()}) }},
+	}
+	for _, step := range steps {
+		if !step.enabled {
+			continue
+		}
+		if err := step.run(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
 		}
-		server.Run()
 	}
+
+	return exitOk
 }
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "no_command",
+			args:           []string{},
+			expectedCode:   exitUsage,
+			expectedStderr: "Usage: whoishiring <command>",
+		},
+		{
+			name:           "help",
+			args:           []string{"help"},
+			expectedCode:   exitOk,
+			expectedStderr: "backfill-headers",
+		},
+		{
+			name:           "unknown_command",
+			args:           []string{"nope"},
+			expectedCode:   exitUsage,
+			expectedStderr: `unknown command "nope"`,
+		},
+		{
+			name:           "command_help",
+			args:           []string{"search", "-h"},
+			expectedCode:   exitOk,
+			expectedStderr: "Usage: whoishiring search [flags] <query>",
+		},
+		{
+			name:           "unknown_flag",
+			args:           []string{"sync", "-nope"},
+			expectedCode:   exitUsage,
+			expectedStderr: "flag provided but not defined: -nope",
+		},
+		{
+			name:           "invalid_config",
+			args:           []string{"serve", "-addr", "8080"},
+			expectedCode:   exitUsage,
+			expectedStderr: `invalid addr "8080"`,
+		},
+		{
+			name:           "sync_invalid_window",
+			args:           []string{"sync", "-window", "0"},
+			expectedCode:   exitUsage,
+			expectedStderr: "window must be greater than 0",
+		},
+		{
+			name:           "verify_negative_stalest",
+			args:           []string{"verify", "-stalest", "-1"},
+			expectedCode:   exitUsage,
+			expectedStderr: "stalest must not be negative",
+		},
+		{
+			name:           "search_without_query",
+			args:           []string{"search"},
+			expectedCode:   exitUsage,
+			expectedStderr: "search query is required",
+		},
+		{
+			name:           "search",
+			args:           []string{"search", "golang"},
+			expectedCode:   exitOk,
+			expectedStdout: "No jobs found.",
+		},
+		{
+			name:           "stats",
+			args:           []string{"stats"},
+			expectedCode:   exitOk,
+			expectedStdout: "STORY",
+		},
+		{
+			name:           "export_without_stories",
+			args:           []string{"export"},
+			expectedCode:   exitError,
+			expectedStderr: "story not found",
+		},
+		{
+			name:           "export_unknown_format",
+			args:           []string{"export", "-format", "xml"},
+			expectedCode:   exitUsage,
+			expectedStderr: `unknown export format "xml"`,
+		},
+		{
+			name:           "import_without_files",
+			args:           []string{"import"},
+			expectedCode:   exitUsage,
+			expectedStderr: "at least one file to import is required",
+		},
+		{
+			name:           "migrate_status",
+			args:           []string{"migrate", "status"},
+			expectedCode:   exitOk,
+			expectedStdout: "pending",
+		},
+		{
+			name:           "migrate_unknown_action",
+			args:           []string{"migrate", "down"},
+			expectedCode:   exitUsage,
+			expectedStderr: `unknown migrate action "down"`,
+		},
+		{
+			name:           "config_flag_before_command",
+			args:           []string{"-addr", ":9090", "stats"},
+			expectedCode:   exitOk,
+			expectedStdout: "STORY",
+		},
+		{
+			name:           "config_flag_without_command",
+			args:           []string{"-addr", ":9090"},
+			expectedCode:   exitUsage,
+			expectedStderr: "Usage: whoishiring <command>",
+		},
+		{
+			name:           "config_flag_before_unknown_command",
+			args:           []string{"-addr", ":9090", "nope"},
+			expectedCode:   exitUsage,
+			expectedStderr: `unknown command "nope"`,
+		},
+		{
+			name:           "deprecated_flag_with_argument",
+			args:           []string{"-sync", "nope"},
+			expectedCode:   exitUsage,
+			expectedStderr: `unexpected argument "nope"`,
+		},
+		{
+			name:           "deprecated_flag",
+			args:           []string{"-migrate-only"},
+			expectedCode:   exitOk,
+			expectedStderr: `-migrate-only is deprecated, use "whoishiring migrate" instead`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// keep every run away from the default database file
+			t.Setenv(envDBPath, filepath.Join(t.TempDir(), "test.db"))
+
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), tt.args, &stdout, &stderr)
+			if code != tt.expectedCode {
+				t.Fatalf("expected exit code %d, got %d: %s", tt.expectedCode, code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.expectedStdout) {
+				t.Fatalf("expected stdout to contain %q, got %q", tt.expectedStdout, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tt.expectedStderr) {
+				t.Fatalf("expected stderr to contain %q, got %q", tt.expectedStderr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_dbFlagBeforeCommand(t *testing.T) {
+	t.Setenv(envDBPath, filepath.Join(t.TempDir(), "env.db"))
+	dbPath := filepath.Join(t.TempDir(), "flag.db")
+
+	var stdout, stderr bytes.Buffer
+	if code := run(context.Background(), []string{"-db", dbPath, "migrate", "status"}, &stdout, &stderr); code != exitOk {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOk, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "pending") {
+		t.Fatalf("expected the migrate status, got %q", stdout.String())
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Fatalf("expected the database of -db to be used: %v", err)
+	}
+}
+
+func TestRunServe_autoSyncEmptyDB(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := &HNStore{db: db}
+
+	story := &ApiStory{Id: 20, Title: "Ask HN: Who is hiring? (October 2026)", Time: postedOn(time.October, 1), Kids: []uint64{21}}
+	items := map[uint64]any{
+		20: story,
+		21: ApiJob{Id: 21, Text: "Acme | Go | Remote"},
+	}
+	api := newFakeHN(t, []uint64{20}, items, nil)
+	defer api.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	env := &commandEnv{
+		ctx:   ctx,
+		cfg:   &Config{Addr: "127.0.0.1:0", BaseURL: api.URL},
+		store: store,
+		db:    db,
+	}
+	opts := &serveOptions{autoSync: time.Hour, fetch: &fetchOptions{concurrency: 1}}
+	if err := runServe(env, opts); err != nil {
+		t.Fatalf("runServe() failed: %v", err)
+	}
+
+	latest, err := store.GetLatestStory()
+	if err != nil {
+		t.Fatalf("GetLatestStory() failed: %v", err)
+	}
+	if latest.HnId != story.Id {
+		t.Errorf("expected story %d to be synced, got %d", story.Id, latest.HnId)
+	}
+	if slices.Contains(api.Requests()[1:], "/user/whoishiring.json") {
+		t.Error("expected the scheduler not to sync again right away")
+	}
+}
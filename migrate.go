@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed migrations/*.sql
+var embedMigrations embed.FS
+
+var (
+	ErrDatabaseAhead = errors.New("database schema is newer than this binary")
+	ErrNoFts5        = errors.New(`SQLite FTS5 is not available, build with "-tags sqlite_fts5"`)
+)
+
+// checkFts5 returns ErrNoFts5 if the SQLite library was compiled without the
+// FTS5 extension, which go-sqlite3 only includes with the sqlite_fts5 build
+// tag. Job search and its migrations need it.
+func checkFts5(ctx context.Context, db *sqlx.DB) error {
+	// Temporary tables only exist on the connection that created them.
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check for fts5: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `CREATE VIRTUAL TABLE temp.fts5_probe USING fts5(text)`); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return ErrNoFts5
+		}
+		return fmt.Errorf("failed to check for fts5: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `DROP TABLE temp.fts5_probe`); err != nil {
+		return fmt.Errorf("failed to check for fts5: %w", err)
+	}
+
+	return nil
+}
+
+// newMigrationProvider creates a goose provider for the embedded migrations.
+func newMigrationProvider(db *sqlx.DB) (*goose.Provider, error) {
+	fsys, err := fs.Sub(embedMigrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db.DB, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration provider: %w", err)
+	}
+
+	return provider, nil
+}
+
+// migrateUp applies all pending embedded migrations and returns the applied
+// migrations. It returns ErrDatabaseAhead if the database has a migration
+// this binary doesn't know about, since running older code against a newer
+// schema is not supported, and ErrNoFts5 if SQLite lacks the FTS5 extension.
+func migrateUp(ctx context.Context, db *sqlx.DB) ([]*goose.MigrationResult, error) {
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		return nil, err
+	}
+
+	dbVersion, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database version: %w", err)
+	}
+
+	sources := provider.ListSources()
+	latestVersion := sources[len(sources)-1].Version
+	if dbVersion > latestVersion {
+		return nil, fmt.Errorf("%w: database is at version %d, latest known version is %d",
+			ErrDatabaseAhead, dbVersion, latestVersion)
+	}
+
+	if err := checkFts5(ctx, db); err != nil {
+		return nil, err
+	}
+
+	results, err := provider.Up(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return results, nil
+}
+
+// printMigrationStatus writes the state of every embedded migration to w.
+func printMigrationStatus(ctx context.Context, db *sqlx.DB, w io.Writer) error {
+	provider, err := newMigrationProvider(db)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get migration status: %w", err)
+	}
+
+	for _, s := range statuses {
+		appliedAt := ""
+		if s.State == goose.StateApplied {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%-8s %-19s %s\n", s.State, appliedAt, s.Source.Path)
+	}
+
+	return nil
+}
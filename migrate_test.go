@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMigrateUp(t *testing.T) {
+	t.Run("already_up_to_date", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		results, err := migrateUp(context.Background(), db)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("expected no migrations to be applied, got %d", len(results))
+		}
+	})
+
+	t.Run("database_ahead_of_binary", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		_, err := db.Exec(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, 1)`, 99990101000000)
+		if err != nil {
+			t.Fatalf("failed to insert future version: %v", err)
+		}
+
+		_, err = migrateUp(context.Background(), db)
+		if !errors.Is(err, ErrDatabaseAhead) {
+			t.Fatalf("expected error %v, got %v", ErrDatabaseAhead, err)
+		}
+	})
+}
+
+func TestPrintMigrationStatus(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	var buf bytes.Buffer
+	if err := printMigrationStatus(context.Background(), db, &buf); err != nil {
+		t.Fatalf("printMigrationStatus() failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	entries, err := embedMigrations.ReadDir("migrations")
+	if err != nil {
+		t.Fatalf("failed to read embedded migrations: %v", err)
+	}
+	if len(lines) != len(entries) {
+		t.Fatalf("expected %d status lines, got %d", len(entries), len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "applied") {
+			t.Fatalf("expected applied migration, got %q", line)
+		}
+	}
+}
+
+func TestCheckFts5(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if err := checkFts5(context.Background(), db); err != nil {
+		t.Fatalf("expected fts5 to be available, got %v", err)
+	}
+}
+
+func TestMigrateUp_foreignKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	if _, err := db.Exec(`PRAGMA foreign_keys=ON`); err != nil {
+		t.Fatalf("failed to enable foreign keys: %v", err)
+	}
+
+	store := NewHNStore(db)
+	story, _ := setUpStoryWithJob(t, store)
+	if err := store.SetCurrentStory(storyKindHiring, story.HnId); err != nil {
+		t.Fatalf("SetCurrentStory() failed: %v", err)
+	}
+}
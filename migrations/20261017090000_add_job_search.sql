@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE hiring_job_fts USING fts5(text, content='hiring_job', content_rowid='id', tokenize='unicode61');
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER hiring_job_fts_ai AFTER INSERT ON hiring_job BEGIN
+    INSERT INTO hiring_job_fts(rowid, text) VALUES (new.id, new.text);
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER hiring_job_fts_au AFTER UPDATE OF text ON hiring_job BEGIN
+    INSERT INTO hiring_job_fts(hiring_job_fts, rowid, text) VALUES ('delete', old.id, old.text);
+    INSERT INTO hiring_job_fts(rowid, text) VALUES (new.id, new.text);
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER hiring_job_fts_ad AFTER DELETE ON hiring_job BEGIN
+    INSERT INTO hiring_job_fts(hiring_job_fts, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO hiring_job_fts(hiring_job_fts) VALUES ('rebuild');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER hiring_job_fts_ad;
+DROP TRIGGER hiring_job_fts_au;
+DROP TRIGGER hiring_job_fts_ai;
+DROP TABLE hiring_job_fts;
+-- +goose StatementEnd
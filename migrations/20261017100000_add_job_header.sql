@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE hiring_job ADD COLUMN company TEXT NOT NULL DEFAULT '';
+ALTER TABLE hiring_job ADD COLUMN remote INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE hiring_job ADD COLUMN onsite INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE hiring_job ADD COLUMN hybrid INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE hiring_job ADD COLUMN visa INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE hiring_job ADD COLUMN salary_min INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE hiring_job ADD COLUMN salary_max INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE hiring_job ADD COLUMN salary_currency TEXT NOT NULL DEFAULT '';
+CREATE TABLE hiring_job_role (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    hiring_job_hn_id INTEGER NOT NULL,
+    role TEXT NOT NULL
+);
+CREATE INDEX hjr_hn_id_index ON hiring_job_role (hiring_job_hn_id);
+CREATE TABLE hiring_job_location (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    hiring_job_hn_id INTEGER NOT NULL,
+    location TEXT NOT NULL
+);
+CREATE INDEX hjl_hn_id_index ON hiring_job_location (hiring_job_hn_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE hiring_job_location;
+DROP TABLE hiring_job_role;
+ALTER TABLE hiring_job DROP COLUMN salary_currency;
+ALTER TABLE hiring_job DROP COLUMN salary_max;
+ALTER TABLE hiring_job DROP COLUMN salary_min;
+ALTER TABLE hiring_job DROP COLUMN visa;
+ALTER TABLE hiring_job DROP COLUMN hybrid;
+ALTER TABLE hiring_job DROP COLUMN onsite;
+ALTER TABLE hiring_job DROP COLUMN remote;
+ALTER TABLE hiring_job DROP COLUMN company;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE hiring_job ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE hiring_job ADD COLUMN edited_at INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE hiring_job_revision (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    hiring_job_hn_id INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    time INTEGER NOT NULL
+);
+CREATE INDEX hjrev_hn_id_index ON hiring_job_revision (hiring_job_hn_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE hiring_job_revision;
+ALTER TABLE hiring_job DROP COLUMN edited_at;
+ALTER TABLE hiring_job DROP COLUMN content_hash;
+-- +goose StatementEnd
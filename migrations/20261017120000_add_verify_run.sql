@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE hiring_job ADD COLUMN last_verified_at INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX hj_last_verified_at_index ON hiring_job (last_verified_at);
+CREATE TABLE verify_run (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    target TEXT NOT NULL,
+    started_at INTEGER NOT NULL,
+    finished_at INTEGER NOT NULL DEFAULT 0,
+    checked INTEGER NOT NULL DEFAULT 0,
+    changed INTEGER NOT NULL DEFAULT 0,
+    edited INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    interrupted INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE verify_run_job (
+    id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    verify_run_id INTEGER NOT NULL,
+    hiring_job_hn_id INTEGER NOT NULL,
+    status INTEGER NOT NULL
+);
+CREATE INDEX vrj_verify_run_id_index ON verify_run_job (verify_run_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE verify_run_job;
+DROP TABLE verify_run;
+DROP INDEX hj_last_verified_at_index;
+ALTER TABLE hiring_job DROP COLUMN last_verified_at;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE backfill_submission (
+    hn_id INTEGER PRIMARY KEY,
+    thread INTEGER NOT NULL DEFAULT 0,
+    title TEXT NOT NULL DEFAULT '',
+    month TEXT NOT NULL DEFAULT '',
+    time INTEGER NOT NULL,
+    completed_at INTEGER
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE backfill_submission;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE hiring_story ADD COLUMN kind INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX hiring_story_kind_time ON hiring_story (kind, time);
+
+-- backfills only kept "Who is hiring?" stories, other submissions are
+-- checked again to find the stories of the other kinds.
+ALTER TABLE backfill_submission ADD COLUMN kind INTEGER NOT NULL DEFAULT 0;
+DELETE FROM backfill_submission WHERE thread=0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM backfill_submission WHERE kind<>0;
+ALTER TABLE backfill_submission DROP COLUMN kind;
+
+DROP INDEX hiring_story_kind_time;
+ALTER TABLE hiring_story DROP COLUMN kind;
+-- +goose StatementEnd
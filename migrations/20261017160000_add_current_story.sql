@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- foreign keys can only reference unique columns
+CREATE UNIQUE INDEX hiring_story_hn_id_index ON hiring_story (hn_id);
+CREATE TABLE current_story (
+    kind INTEGER PRIMARY KEY,
+    hiring_story_hn_id INTEGER NOT NULL,
+    FOREIGN KEY(hiring_story_hn_id) REFERENCES hiring_story(hn_id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE current_story;
+DROP INDEX hiring_story_hn_id_index;
+-- +goose StatementEnd
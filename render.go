@@ -0,0 +1,244 @@
+package main
+
+import (
+	"html"
+	"slices"
+	"strings"
+)
+
+// jobNode is a node of a parsed job text. Text nodes have an empty tag.
+type jobNode struct {
+	tag      string
+	text     string
+	href     string
+	children []*jobNode
+}
+
+// parseJobText parses the html of a job text into its blocks: paragraphs and
+// preformatted blocks. Paragraphs are started by <p> tags or new lines
+// outside of <pre>, and empty ones are dropped. Tags left open are closed at
+// the end of their block.
+func parseJobText(text string) []*jobNode {
+	var blocks []*jobNode
+	// open is the stack of open nodes, starting with the current block.
+	var open []*jobNode
+
+	endBlock := func() {
+		open = open[:0]
+	}
+	inPre := func() bool {
+		return len(open) > 0 && open[0].tag == "pre"
+	}
+	// current returns the innermost open node, starting a paragraph if no
+	// block is open.
+	current := func() *jobNode {
+		if len(open) == 0 {
+			p := &jobNode{tag: "p"}
+			blocks = append(blocks, p)
+			open = append(open, p)
+		}
+		return open[len(open)-1]
+	}
+
+	for tok := range hnTokens(text) {
+		switch tok.kind {
+		case hnText:
+			if inPre() {
+				parent := current()
+				parent.children = append(parent.children, &jobNode{text: tok.text})
+				continue
+			}
+			for i, line := range strings.Split(tok.text, "\n") {
+				if i > 0 {
+					endBlock()
+				}
+				if len(open) == 0 && strings.TrimSpace(line) == "" {
+					continue
+				}
+				parent := current()
+				parent.children = append(parent.children, &jobNode{text: line})
+			}
+		case hnStartTag:
+			switch {
+			case tok.tag == "p":
+				endBlock()
+			case tok.tag == "pre":
+				endBlock()
+				pre := &jobNode{tag: "pre"}
+				blocks = append(blocks, pre)
+				open = append(open, pre)
+			case tok.tag == "a" && (tok.href == "" || slices.ContainsFunc(open, isLink)):
+				// links without a safe href or inside another link are
+				// rendered as their text
+			default:
+				parent := current()
+				node := &jobNode{tag: tok.tag, href: tok.href}
+				parent.children = append(parent.children, node)
+				open = append(open, node)
+			}
+		case hnEndTag:
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].tag == tok.tag {
+					open = open[:i]
+					break
+				}
+			}
+		}
+	}
+
+	nonEmpty := blocks[:0]
+	for _, b := range blocks {
+		if b.tag == "pre" || strings.TrimSpace(nodeText(b)) != "" {
+			nonEmpty = append(nonEmpty, b)
+		}
+	}
+	return nonEmpty
+}
+
+func isLink(n *jobNode) bool {
+	return n.tag == "a"
+}
+
+// nodeText returns the text of n and its children.
+func nodeText(n *jobNode) string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+// renderJobHTML renders the html of a job text with the classes used by the
+// templates, keeping only safe markup.
+func renderJobHTML(text string) string {
+	var b strings.Builder
+	for _, block := range parseJobText(text) {
+		if block.tag == "pre" {
+			b.WriteString(`<pre class="my-2 overflow-x-auto">`)
+			writeNodesHTML(&b, block.children)
+			b.WriteString(`</pre>`)
+			continue
+		}
+		b.WriteString(`<p class="my-2">`)
+		writeNodesHTML(&b, trimNodes(block.children))
+		b.WriteString(`</p>`)
+	}
+	return b.String()
+}
+
+func writeNodesHTML(b *strings.Builder, nodes []*jobNode) {
+	for _, n := range nodes {
+		switch n.tag {
+		case "":
+			b.WriteString(html.EscapeString(n.text))
+		case "a":
+			b.WriteString(`<a href="` + html.EscapeString(n.href) + `" rel="nofollow noopener">`)
+			writeNodesHTML(b, n.children)
+			b.WriteString(`</a>`)
+		default:
+			b.WriteString("<" + n.tag + ">")
+			writeNodesHTML(b, n.children)
+			b.WriteString("</" + n.tag + ">")
+		}
+	}
+}
+
+// renderJobMarkdown renders a job text as Markdown, with code blocks fenced
+// and links, italics and inline code in their Markdown syntax.
+func renderJobMarkdown(text string) string {
+	blocks := parseJobText(text)
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.tag == "pre" {
+			code := strings.TrimRight(nodeText(block), "\n")
+			fence := "```"
+			for strings.Contains(code, fence) {
+				fence += "`"
+			}
+			parts = append(parts, fence+"\n"+code+"\n"+fence)
+			continue
+		}
+		var b strings.Builder
+		writeNodesMarkdown(&b, trimNodes(block.children))
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`,
+)
+
+var markdownURLEscaper = strings.NewReplacer(`(`, "%28", `)`, "%29", ` `, "%20")
+
+func writeNodesMarkdown(b *strings.Builder, nodes []*jobNode) {
+	for _, n := range nodes {
+		switch n.tag {
+		case "":
+			b.WriteString(markdownEscaper.Replace(n.text))
+		case "i":
+			if strings.TrimSpace(nodeText(n)) == "" {
+				writeNodesMarkdown(b, n.children)
+				continue
+			}
+			b.WriteString("*")
+			writeNodesMarkdown(b, n.children)
+			b.WriteString("*")
+		case "code":
+			code := nodeText(n)
+			fence := "`"
+			for strings.Contains(code, fence) {
+				fence += "`"
+			}
+			if len(fence) > 1 {
+				code = " " + code + " "
+			}
+			b.WriteString(fence + code + fence)
+		case "a":
+			if nodeText(n) == n.href {
+				b.WriteString("<" + n.href + ">")
+				continue
+			}
+			b.WriteString("[")
+			writeNodesMarkdown(b, n.children)
+			b.WriteString("](" + markdownURLEscaper.Replace(n.href) + ")")
+		default:
+			writeNodesMarkdown(b, n.children)
+		}
+	}
+}
+
+// renderJobPlain renders the text of a job without markup, with paragraphs
+// separated by blank lines.
+func renderJobPlain(text string) string {
+	blocks := parseJobText(text)
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.tag == "pre" {
+			parts = append(parts, strings.TrimRight(nodeText(block), "\n"))
+			continue
+		}
+		parts = append(parts, strings.TrimSpace(nodeText(block)))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// trimNodes returns nodes without the leading and trailing spaces of the
+// paragraph they make up.
+func trimNodes(nodes []*jobNode) []*jobNode {
+	if len(nodes) == 0 {
+		return nodes
+	}
+	trimmed := make([]*jobNode, len(nodes))
+	copy(trimmed, nodes)
+	if first := trimmed[0]; first.tag == "" {
+		trimmed[0] = &jobNode{text: strings.TrimLeft(first.text, " \t\r")}
+	}
+	if last := trimmed[len(trimmed)-1]; last.tag == "" {
+		trimmed[len(trimmed)-1] = &jobNode{text: strings.TrimRight(last.text, " \t\r")}
+	}
+	return trimmed
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderJobHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "hn_markup",
+			input:    `Acme | Remote<p>We use <i>Go</i> &amp; <code>sqlite</code><p><a href="https:&#x2F;&#x2F;acme.com&#x2F;jobs" rel="nofollow">https:&#x2F;&#x2F;acme.com&#x2F;jobs</a>`,
+			expected: `<p class="my-2">Acme | Remote</p><p class="my-2">We use <i>Go</i> &amp; <code>sqlite</code></p><p class="my-2"><a href="https://acme.com/jobs" rel="nofollow noopener">https://acme.com/jobs</a></p>`,
+		},
+		{
+			name:     "new_lines_start_paragraphs",
+			input:    "Foo.\nBar:<p>Test",
+			expected: `<p class="my-2">Foo.</p><p class="my-2">Bar:</p><p class="my-2">Test</p>`,
+		},
+		{
+			name:     "no_empty_paragraphs",
+			input:    "<p>Foo.\n\n<p> <p><i></i><p>Bar\n",
+			expected: `<p class="my-2">Foo.</p><p class="my-2">Bar</p>`,
+		},
+		{
+			name:     "code_block_keeps_new_lines",
+			input:    "Example:<p><pre><code>  if x &lt; 1 {\n\n    return\n  }\n</code></pre>After",
+			expected: "<p class=\"my-2\">Example:</p><pre class=\"my-2 overflow-x-auto\"><code>  if x &lt; 1 {\n\n    return\n  }\n</code></pre><p class=\"my-2\">After</p>",
+		},
+		{
+			name:     "script_tag",
+			input:    `Hi<script>alert("xss")</script> there`,
+			expected: `<p class="my-2">Hi there</p>`,
+		},
+		{
+			name:     "uppercase_script_tag",
+			input:    `<SCRIPT src="https://evil.com/x.js"></SCRIPT>ok`,
+			expected: `<p class="my-2">ok</p>`,
+		},
+		{
+			name:     "unclosed_script_tag",
+			input:    `Hi<script>alert(1)`,
+			expected: `<p class="my-2">Hi</p>`,
+		},
+		{
+			name:     "event_handler",
+			input:    `<img src=x onerror="alert(1)"><i onmouseover="alert(1)">hover</i>`,
+			expected: `<p class="my-2"><i>hover</i></p>`,
+		},
+		{
+			name:     "javascript_href",
+			input:    `<a href="javascript:alert(1)">click</a>`,
+			expected: `<p class="my-2">click</p>`,
+		},
+		{
+			name:     "encoded_javascript_href",
+			input:    `<a href="&#x6A;avascript&#x3A;alert(1)">click</a>`,
+			expected: `<p class="my-2">click</p>`,
+		},
+		{
+			name:     "javascript_href_with_control_chars",
+			input:    "<a href=\"java\tscript:alert(1)\">click</a>",
+			expected: `<p class="my-2">click</p>`,
+		},
+		{
+			name:     "data_href",
+			input:    `<a href="data:text/html,<script>alert(1)</script>">click</a>`,
+			expected: `<p class="my-2">click</p>`,
+		},
+		{
+			name:     "relative_href",
+			input:    `<a href="/login">login</a>`,
+			expected: `<p class="my-2">login</p>`,
+		},
+		{
+			name:     "href_breaking_out_of_attribute",
+			input:    `<a href='https://acme.com/"onmouseover="alert(1)'>x</a>`,
+			expected: `<p class="my-2"><a href="https://acme.com/%22onmouseover=%22alert%281%29" rel="nofollow noopener">x</a></p>`,
+		},
+		{
+			name:     "extra_link_attributes",
+			input:    `<a href="https://acme.com" target="_self" style="color:red" rel="opener">x</a>`,
+			expected: `<p class="my-2"><a href="https://acme.com" rel="nofollow noopener">x</a></p>`,
+		},
+		{
+			name:     "nested_links",
+			input:    `<a href="https://a.com">a<a href="https://b.com">b</a></a>`,
+			expected: `<p class="my-2"><a href="https://a.com" rel="nofollow noopener">ab</a></p>`,
+		},
+		{
+			name:     "unclosed_tags",
+			input:    `<i><code>x`,
+			expected: `<p class="my-2"><i><code>x</code></i></p>`,
+		},
+		{
+			name:     "unopened_end_tags",
+			input:    `x</i></pre></div>`,
+			expected: `<p class="my-2">x</p>`,
+		},
+		{
+			name:     "paragraph_closes_inline_tags",
+			input:    `<i>a<p>b</i>`,
+			expected: `<p class="my-2"><i>a</i></p><p class="my-2">b</p>`,
+		},
+		{
+			name:     "paragraph_attributes",
+			input:    `a<p class="x" onclick="alert(1)">b</p>`,
+			expected: `<p class="my-2">a</p><p class="my-2">b</p>`,
+		},
+		{
+			name:     "iframe_and_style",
+			input:    `<style>body{display:none}</style><iframe src="https://evil.com"></iframe>ok`,
+			expected: `<p class="my-2">ok</p>`,
+		},
+		{
+			name:     "comments",
+			input:    `a<!-- <script>alert(1)</script> -->b<!-- unclosed`,
+			expected: `<p class="my-2">ab</p>`,
+		},
+		{
+			name:     "text_that_looks_like_tags",
+			input:    `1 < 2 and <3 <`,
+			expected: `<p class="my-2">1 &lt; 2 and &lt;3 &lt;</p>`,
+		},
+		{
+			name:     "unterminated_tag",
+			input:    `a <i onclick="alert(1)`,
+			expected: `<p class="my-2">a &lt;i onclick=&#34;alert(1)</p>`,
+		},
+		{
+			name:     "escaped_markup_stays_escaped",
+			input:    `&lt;script&gt;alert(1)&lt;&#x2F;script&gt;`,
+			expected: `<p class="my-2">&lt;script&gt;alert(1)&lt;/script&gt;</p>`,
+		},
+		{
+			name:     "empty",
+			input:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := renderJobHTML(tt.input)
+			if res != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestRenderJobMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "paragraphs",
+			input:    "Acme | Remote<p>We use <i>Go</i> &amp; <code>sqlite</code>\nApply",
+			expected: "Acme | Remote\n\nWe use *Go* & `sqlite`\n\nApply",
+		},
+		{
+			name:     "links",
+			input:    `<a href="https:&#x2F;&#x2F;acme.com&#x2F;jobs">https:&#x2F;&#x2F;acme.com&#x2F;jobs</a> or <a href="https://acme.com/(careers)">careers</a>`,
+			expected: "<https://acme.com/jobs> or [careers](https://acme.com/%28careers%29)",
+		},
+		{
+			name:     "escapes_markdown",
+			input:    `C++ *and* [rust] &lt;b&gt; back_end`,
+			expected: `C++ \*and\* \[rust\] \<b> back\_end`,
+		},
+		{
+			name:     "code_block",
+			input:    "Example:<p><pre><code>  a := `x`\n  b := &quot;```&quot;\n</code></pre>",
+			expected: "Example:\n\n````\n  a := `x`\n  b := \"```\"\n````",
+		},
+		{
+			name:     "inline_code_with_backtick",
+			input:    "<code>a`b</code>",
+			expected: "`` a`b ``",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := renderJobMarkdown(tt.input)
+			if res != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestRenderJobPlain(t *testing.T) {
+	input := "Acme | Remote<p>We use <i>Go</i> &amp; <code>sqlite</code>" +
+		`<p>Apply at <a href="https://acme.com/jobs">acme.com</a> or <a href="https://acme.com">https://acme.com</a>` +
+		"<p><pre><code>  go test ./...\n</code></pre>"
+	expected := "Acme | Remote\n\nWe use Go & sqlite\n\n" +
+		"Apply at acme.com or https://acme.com\n\n" +
+		"  go test ./..."
+
+	if res := renderJobPlain(input); res != expected {
+		t.Errorf("expected %q, got %q", expected, res)
+	}
+}
+
+// largeJobText returns a job text of about 100KB using every tag HN posts
+// contain.
+func largeJobText() string {
+	var b strings.Builder
+	b.WriteString("Acme Corp | Senior Go Engineer | Berlin, Germany | REMOTE (EU) | Full-time")
+	for range 250 {
+		b.WriteString("<p>We&#x27;re building <i>fast</i> &amp; reliable infrastructure with <code>Go</code> and Postgres.")
+		b.WriteString("\nApply at <a href=\"https:&#x2F;&#x2F;acme.com&#x2F;jobs?id=1&amp;src=hn\" rel=\"nofollow\">https:&#x2F;&#x2F;acme.com&#x2F;jobs?id=1&amp;src=hn</a>")
+		b.WriteString("<p><pre><code>  func main() {\n    fmt.Println(&quot;hello&quot;)\n  }\n</code></pre>")
+	}
+	return b.String()
+}
+
+func BenchmarkRenderJobHTML(b *testing.B) {
+	text := largeJobText()
+	b.SetBytes(int64(len(text)))
+	for b.Loop() {
+		renderJobHTML(text)
+	}
+}
+
+func BenchmarkRenderJobMarkdown(b *testing.B) {
+	text := largeJobText()
+	b.SetBytes(int64(len(text)))
+	for b.Loop() {
+		renderJobMarkdown(text)
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"cmp"
 	"database/sql"
 	"errors"
 	"fmt"
+	"html/template"
 	"strings"
 	"time"
 
@@ -13,16 +15,22 @@ import (
 var ZeroRowsUpdated = errors.New("zero rows updated")
 
 type HnStory struct {
-	HnId  uint64 `db:"hn_id"`
-	Title string `db:"title"`
-	Time  uint64 `db:"time"`
+	HnId  uint64    `db:"hn_id" json:"hn_id"`
+	Title string    `db:"title" json:"title"`
+	Time  uint64    `db:"time" json:"time"`
+	Kind  StoryKind `db:"kind" json:"kind"`
 }
 
-// IsInSameMonth returns true if HnStory is in the same month as the target time
-func (s HnStory) IsInSameMonth(t time.Time) bool {
-	storyTime := time.Unix(int64(s.Time), 0).UTC()
-	t = t.UTC()
-	return storyTime.Year() == t.Year() && storyTime.Month() == t.Month()
+// HnStoryStats is an HnStory along with counts of its OK jobs.
+type HnStoryStats struct {
+	HnStory
+	Jobs uint64 `db:"jobs"`
+	Seen uint64 `db:"seen"`
+}
+
+// Unseen returns the number of OK jobs that have not been seen.
+func (s HnStoryStats) Unseen() uint64 {
+	return s.Jobs - s.Seen
 }
 
 type HnJob struct {
@@ -32,31 +40,63 @@ type HnJob struct {
 	Seen   uint8  `db:"seen"`
 	Saved  uint8  `db:"saved"`
 	Status uint8  `db:"status"`
+	// EditedAt is when an edit of the job was last detected, 0 if never.
+	EditedAt uint64 `db:"edited_at"`
+	StoryId  uint64 `db:"hiring_story_hn_id"`
+	Notes    string `db:"notes"`
 }
 
-// TransformedText returns HnJob Text with updated html.
-func (j *HnJob) TransformedText() string {
-	var result string
+// JobPatch holds the user fields of a job to update. Nil fields are left
+// unchanged.
+type JobPatch struct {
+	Seen  *bool
+	Saved *bool
+	Notes *string
+}
+
+// IsEmpty returns true if the patch doesn't change anything.
+func (p JobPatch) IsEmpty() bool {
+	return p.Seen == nil && p.Saved == nil && p.Notes == nil
+}
 
-	jobTxt := strings.TrimSpace(j.Text)
+// TransformedText returns HnJob Text rendered as safe html, followed by a
+// link to the post.
+func (j *HnJob) TransformedText() template.HTML {
 	postedLink := fmt.Sprintf(
 		`<p class="my-2"><a href="https://news.ycombinator.com/item?id=%d">Posted: %s</a></p>`,
 		j.HnId,
 		time.Unix(int64(j.Time), 0),
 	)
 
-	if jobTxt == "" {
-		return postedLink
-	}
+	return template.HTML(renderJobHTML(j.Text) + postedLink)
+}
 
-	for lineVal := range strings.SplitSeq(jobTxt, "\n") {
-		for parVal := range strings.SplitSeq(lineVal, "<p>") {
-			result = result + fmt.Sprintf(`<p class="my-2">%s</p>`, parVal)
-		}
-	}
+// HnSavedJob is a saved HnJob along with the story it was posted in.
+type HnSavedJob struct {
+	HnJob
+	StoryHnId  uint64 `db:"story_hn_id"`
+	StoryTitle string `db:"story_title"`
+}
 
-	result = result + postedLink
-	return result
+// VerifyRun is the result of a run of the verify process.
+type VerifyRun struct {
+	Id          int64  `db:"id"`
+	Target      string `db:"target"`
+	StartedAt   int64  `db:"started_at"`
+	FinishedAt  int64  `db:"finished_at"`
+	Checked     uint64 `db:"checked"`
+	Changed     uint64 `db:"changed"`
+	Edited      uint64 `db:"edited"`
+	Failed      uint64 `db:"failed"`
+	Interrupted bool   `db:"interrupted"`
+}
+
+// VerifyRunJob is a job whose status was changed by a verify run.
+type VerifyRunJob struct {
+	HnId       uint64 `db:"hn_id"`
+	Status     uint8  `db:"status"`
+	Saved      uint8  `db:"saved"`
+	StoryTitle string `db:"story_title"`
 }
 
 type HNStore struct {
@@ -65,10 +105,10 @@ type HNStore struct {
 
 // CreateStory inserts a new WhoIsHiring story into the db.
 func (s *HNStore) CreateStory(story *HnStory) error {
-	query := `INSERT INTO hiring_story (hn_id, title, time)
-						VALUES (?, ?, ?)`
+	query := `INSERT INTO hiring_story (hn_id, title, time, kind)
+						VALUES (?, ?, ?, ?)`
 
-	_, err := s.db.Exec(query, story.HnId, story.Title, story.Time)
+	_, err := s.db.Exec(query, story.HnId, story.Title, story.Time, story.Kind)
 	if err != nil {
 		return fmt.Errorf("failed to create hiring story: %w", err)
 	}
@@ -78,10 +118,10 @@ func (s *HNStore) CreateStory(story *HnStory) error {
 
 // CreateJob inserts a new WhoIsHiring job into the db.
 func (s *HNStore) CreateJob(job *HnJob, hnStoryId uint64) error {
-	query := `INSERT INTO hiring_job (hn_id, hiring_story_hn_id, text, time, status)
-						VALUES (?, ?, ?, ?, ?)`
+	query := `INSERT INTO hiring_job (hn_id, hiring_story_hn_id, text, time, status, content_hash)
+						VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := s.db.Exec(query, job.HnId, hnStoryId, job.Text, job.Time, job.Status)
+	_, err := s.db.Exec(query, job.HnId, hnStoryId, job.Text, job.Time, job.Status, contentHash(job.Text))
 	if err != nil {
 		return fmt.Errorf("failed to create hiring job: %w", err)
 	}
@@ -89,9 +129,10 @@ func (s *HNStore) CreateJob(job *HnJob, hnStoryId uint64) error {
 	return nil
 }
 
-// GetJobIdsByStoryId retrieves jobs ids for a given story.
-func (s *HNStore) GetJobIdsByStoryId(hnStoryId uint64) (map[uint64]bool, error) {
-	query := `SELECT hn_id FROM hiring_job WHERE hiring_story_hn_id=?`
+// GetJobIdsByStoryId retrieves jobs ids for a given story, along with their
+// status.
+func (s *HNStore) GetJobIdsByStoryId(hnStoryId uint64) (map[uint64]uint8, error) {
+	query := `SELECT hn_id, status FROM hiring_job WHERE hiring_story_hn_id=?`
 
 	rows, err := s.db.Query(query, hnStoryId)
 	if err != nil {
@@ -99,13 +140,14 @@ func (s *HNStore) GetJobIdsByStoryId(hnStoryId uint64) (map[uint64]bool, error)
 	}
 	defer rows.Close()
 
-	ids := make(map[uint64]bool)
+	ids := make(map[uint64]uint8)
 	for rows.Next() {
 		var id uint64
-		if err := rows.Scan(&id); err != nil {
+		var status uint8
+		if err := rows.Scan(&id, &status); err != nil {
 			return nil, fmt.Errorf("failed to scan hiring job ID: %w", err)
 		}
-		ids[id] = true
+		ids[id] = status
 	}
 
 	return ids, nil
@@ -133,49 +175,111 @@ func (s *HNStore) GetOkJobIdsByStoryId(hnStoryId uint64) (map[uint64]bool, error
 	return ids, nil
 }
 
-// GetLatestStory retrieves the latest hiring story from the database.
+// GetLatestStory retrieves the latest "Who is hiring?" story from the
+// database.
 func (s *HNStore) GetLatestStory() (*HnStory, error) {
-	var story HnStory
-	query := "SELECT hn_id, title, time FROM hiring_story ORDER BY time DESC LIMIT 1"
+	return s.GetLatestStoryOfKind(storyKindHiring)
+}
 
-	err := s.db.Get(&story, query)
+// GetLatestStoryOfKind retrieves the current story of a kind, as picked by
+// the latest sync, from the database. When no sync picked one yet, e.g.
+// after a backfill or an import, the latest posted story is returned.
+func (s *HNStore) GetLatestStoryOfKind(kind StoryKind) (*HnStory, error) {
+	var story HnStory
+	query := `SELECT s.hn_id, s.title, s.time, s.kind
+            FROM hiring_story s
+            LEFT JOIN current_story c ON c.hiring_story_hn_id = s.hn_id
+            WHERE s.kind=?
+            ORDER BY c.kind IS NULL, s.time DESC
+            LIMIT 1`
+
+	err := s.db.Get(&story, query, kind)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
 		}
-		return nil, fmt.Errorf("failed to get latest hiring story: %w", err)
+		return nil, fmt.Errorf("failed to get latest %s story: %w", kind, err)
 	}
 
 	return &story, nil
 }
 
+// SetCurrentStory makes the story with hnStoryId the current story of kind,
+// returned by GetLatestStoryOfKind.
+func (s *HNStore) SetCurrentStory(kind StoryKind, hnStoryId uint64) error {
+	query := `INSERT OR REPLACE INTO current_story (kind, hiring_story_hn_id) VALUES (?, ?)`
+	if _, err := s.db.Exec(query, kind, hnStoryId); err != nil {
+		return fmt.Errorf("failed to set current %s story %d: %w", kind, hnStoryId, err)
+	}
+
+	return nil
+}
+
+// GetStory retrieves a hiring story by its Hacker News id.
+func (s *HNStore) GetStory(hnStoryId uint64) (*HnStory, error) {
+	var story HnStory
+	query := "SELECT hn_id, title, time, kind FROM hiring_story WHERE hn_id=?"
+
+	if err := s.db.Get(&story, query, hnStoryId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to get hiring story %d: %w", hnStoryId, err)
+	}
+
+	return &story, nil
+}
+
+// GetStoriesWithStats retrieves all hiring stories, newest first, along with
+// their OK job counts.
+func (s *HNStore) GetStoriesWithStats() ([]HnStoryStats, error) {
+	stories := []HnStoryStats{}
+
+	query := `SELECT s.hn_id, s.title, s.time, s.kind,
+              count(j.hn_id) as jobs,
+              coalesce(sum(j.seen), 0) as seen
+            FROM hiring_story s
+            LEFT JOIN hiring_job j ON j.hiring_story_hn_id = s.hn_id and j.status=?
+            GROUP BY s.hn_id, s.title, s.time, s.kind
+            ORDER BY s.time DESC`
+	if err := s.db.Select(&stories, query, jobStatusOk); err != nil {
+		return nil, fmt.Errorf("failed to select hiring stories: %w", err)
+	}
+
+	return stories, nil
+}
+
 // GetMinMaxJobIDs retrieves the min and max job IDs for a hiring story.
-func (s *HNStore) GetMinMaxJobIDs(hnStoryId uint64) (uint64, uint64, error) {
+func (s *HNStore) GetMinMaxJobIDs(hnStoryId uint64, f JobFilter) (uint64, uint64, error) {
 	var result struct {
-		Min uint64 `db:"min"`
-		Max uint64 `db:"max"`
+		Min sql.NullInt64 `db:"min"`
+		Max sql.NullInt64 `db:"max"`
 	}
 
+	where, args := f.whereClause()
 	query := `SELECT min(hn_id) as min, max(hn_id) as max
             FROM hiring_job
-            WHERE hiring_story_hn_id=? and status=?`
-	if err := s.db.Get(&result, query, hnStoryId, jobStatusOk); err != nil {
+            WHERE hiring_story_hn_id=? and status=?` + where
+	args = append([]any{hnStoryId, jobStatusOk}, args...)
+	if err := s.db.Get(&result, query, args...); err != nil {
 		return 0, 0, fmt.Errorf("failed to get min/max hiring job IDs: %w", err)
 	}
 
-	return result.Min, result.Max, nil
+	return uint64(result.Min.Int64), uint64(result.Max.Int64), nil
 }
 
 // GetFirstJob retrieves first WhoIsHiring job.
-func (s *HNStore) GetFirstJob(hnStoryId uint64) (*HnJob, error) {
+func (s *HNStore) GetFirstJob(hnStoryId uint64, f JobFilter) (*HnJob, error) {
 	var job HnJob
 
-	query := `SELECT hn_id, seen, saved, text, time
+	where, args := f.whereClause()
+	query := `SELECT hn_id, seen, saved, text, time, status, edited_at
             FROM hiring_job
-            WHERE hiring_story_hn_id=? and status=?
+            WHERE hiring_story_hn_id=? and status=?` + where + `
             ORDER BY hn_id DESC
             Limit 1`
-	if err := s.db.Get(&job, query, hnStoryId, jobStatusOk); err != nil {
+	args = append([]any{hnStoryId, jobStatusOk}, args...)
+	if err := s.db.Get(&job, query, args...); err != nil {
 		return nil, fmt.Errorf("failed to select first hiring job: %w", err)
 	}
 
@@ -183,15 +287,17 @@ func (s *HNStore) GetFirstJob(hnStoryId uint64) (*HnJob, error) {
 }
 
 // GetJobAfterID retrieves the next WhoIsHiring job.
-func (s *HNStore) GetJobAfterID(hnStoryId, hnJobId uint64) (*HnJob, error) {
+func (s *HNStore) GetJobAfterID(hnStoryId, hnJobId uint64, f JobFilter) (*HnJob, error) {
 	var job HnJob
 
-	query := `SELECT hn_id, seen, saved, text, time, status
+	where, args := f.whereClause()
+	query := `SELECT hn_id, seen, saved, text, time, status, edited_at
             FROM hiring_job
-            WHERE hiring_story_hn_id=? and status=? and hn_id < ?
+            WHERE hiring_story_hn_id=? and status=? and hn_id < ?` + where + `
             ORDER BY hn_id DESC
             Limit 1`
-	if err := s.db.Get(&job, query, hnStoryId, jobStatusOk, hnJobId); err != nil {
+	args = append([]any{hnStoryId, jobStatusOk, hnJobId}, args...)
+	if err := s.db.Get(&job, query, args...); err != nil {
 		return nil, fmt.Errorf("failed to select next hiring job: %w", err)
 	}
 
@@ -199,15 +305,17 @@ func (s *HNStore) GetJobAfterID(hnStoryId, hnJobId uint64) (*HnJob, error) {
 }
 
 // GetJobBeforeID retrieves the previous WhoIsHiring job.
-func (s *HNStore) GetJobBeforeID(hnStoryId, hnJobId uint64) (*HnJob, error) {
+func (s *HNStore) GetJobBeforeID(hnStoryId, hnJobId uint64, f JobFilter) (*HnJob, error) {
 	var job HnJob
 
-	query := `SELECT hn_id, seen, saved, text, time, status
+	where, args := f.whereClause()
+	query := `SELECT hn_id, seen, saved, text, time, status, edited_at
             FROM hiring_job
-            WHERE hiring_story_hn_id=? and status=? and hn_id > ?
+            WHERE hiring_story_hn_id=? and status=? and hn_id > ?` + where + `
             ORDER BY hn_id ASC
             Limit 1`
-	if err := s.db.Get(&job, query, hnStoryId, jobStatusOk, hnJobId); err != nil {
+	args = append([]any{hnStoryId, jobStatusOk, hnJobId}, args...)
+	if err := s.db.Get(&job, query, args...); err != nil {
 		return nil, fmt.Errorf("failed to select job before id %d: %w", hnJobId, err)
 	}
 
@@ -216,9 +324,430 @@ func (s *HNStore) GetJobBeforeID(hnStoryId, hnJobId uint64) (*HnJob, error) {
 
 // SetJobAsSeen marks a job as seen.
 func (s *HNStore) SetJobAsSeen(hnJobId uint64) error {
-	res, err := s.db.Exec(`UPDATE hiring_job set seen=1 where hn_id=?`, hnJobId)
+	return s.updateJobSeen(hnJobId, 1)
+}
+
+// SetJobUnseen marks a job as not seen.
+func (s *HNStore) SetJobUnseen(hnJobId uint64) error {
+	return s.updateJobSeen(hnJobId, 0)
+}
+
+func (s *HNStore) updateJobSeen(hnJobId uint64, seen uint8) error {
+	res, err := s.db.Exec(`UPDATE hiring_job set seen=? where hn_id=?`, seen, hnJobId)
+	if err != nil {
+		return fmt.Errorf("failed to set hiring job seen value: %w", err)
+	}
+
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affectedRows == 0 {
+		return ZeroRowsUpdated
+	}
+
+	return nil
+}
+
+// SetStoryJobsSeen marks every OK job of a story as seen. It returns the
+// number of jobs that were not seen before.
+func (s *HNStore) SetStoryJobsSeen(hnStoryId uint64) (int64, error) {
+	res, err := s.db.Exec(
+		`UPDATE hiring_job set seen=1 where hiring_story_hn_id=? and status=? and seen=0`,
+		hnStoryId, jobStatusOk,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set hiring jobs of story %d as seen: %w", hnStoryId, err)
+	}
+
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return affectedRows, nil
+}
+
+// SetJobSaved marks a job as saved.
+func (s *HNStore) SetJobSaved(hnJobId uint64) error {
+	return s.updateJobSaved(hnJobId, 1)
+}
+
+// UnsetJobSaved removes a job from the saved jobs.
+func (s *HNStore) UnsetJobSaved(hnJobId uint64) error {
+	return s.updateJobSaved(hnJobId, 0)
+}
+
+func (s *HNStore) updateJobSaved(hnJobId uint64, saved uint8) error {
+	res, err := s.db.Exec(`UPDATE hiring_job set saved=? where hn_id=?`, saved, hnJobId)
+	if err != nil {
+		return fmt.Errorf("failed to set hiring job saved value: %w", err)
+	}
+
+	affectedRows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affectedRows == 0 {
+		return ZeroRowsUpdated
+	}
+
+	return nil
+}
+
+// GetSavedJobs retrieves all saved jobs across all hiring stories.
+func (s *HNStore) GetSavedJobs() ([]HnSavedJob, error) {
+	jobs := []HnSavedJob{}
+
+	query := `SELECT j.hn_id, j.seen, j.saved, j.text, j.time, j.status,
+              s.hn_id as story_hn_id, s.title as story_title
+            FROM hiring_job j
+            JOIN hiring_story s ON s.hn_id = j.hiring_story_hn_id
+            WHERE j.saved=1
+            ORDER BY j.hn_id DESC`
+	if err := s.db.Select(&jobs, query); err != nil {
+		return nil, fmt.Errorf("failed to select saved hiring jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// SearchJobs retrieves OK jobs matching query, ranked by bm25. Jobs from
+// every story are searched when hnStoryId is 0.
+func (s *HNStore) SearchJobs(query string, hnStoryId uint64, limit, offset int) ([]HnJobSearchResult, error) {
+	results := []HnJobSearchResult{}
+
+	match := ftsQuery(query)
+	if match == "" {
+		return results, nil
+	}
+
+	// bm25() is lower for better matches.
+	sqlQuery := `SELECT j.hn_id, j.seen, j.saved, j.text, j.time, j.status,
+              s.hn_id as story_hn_id, s.title as story_title,
+              snippet(hiring_job_fts, 0, ?, ?, '…', 24) as snippet
+            FROM hiring_job_fts
+            JOIN hiring_job j ON j.id = hiring_job_fts.rowid
+            JOIN hiring_story s ON s.hn_id = j.hiring_story_hn_id
+            WHERE hiring_job_fts MATCH ? and j.status=? and (?=0 or j.hiring_story_hn_id=?)
+            ORDER BY bm25(hiring_job_fts), j.hn_id DESC
+            LIMIT ? OFFSET ?`
+	err := s.db.Select(
+		&results, sqlQuery,
+		snippetMatchStart, snippetMatchEnd,
+		match, jobStatusOk, hnStoryId, hnStoryId,
+		limit, offset,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			err = ErrNoFts5
+		}
+		return nil, fmt.Errorf("failed to search hiring jobs: %w", err)
+	}
+
+	return results, nil
+}
+
+// GetExportJobs retrieves the jobs of a story matching f, newest first.
+func (s *HNStore) GetExportJobs(hnStoryId uint64, f ExportFilter) ([]HnJob, error) {
+	jobs := []HnJob{}
+
+	where, args := f.whereClause()
+	query := `SELECT hn_id, seen, saved, text, time, status, notes
+            FROM hiring_job
+            WHERE hiring_story_hn_id=?` + where + `
+            ORDER BY hn_id DESC`
+	if err := s.db.Select(&jobs, query, append([]any{hnStoryId}, args...)...); err != nil {
+		return nil, fmt.Errorf("failed to select hiring jobs to export: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ImportJob upserts an exported job, creating its story if needed. New jobs
+// are inserted as exported. Stored jobs keep their text and status, become
+// seen or saved if j is, and get the notes of j if they have none. This is a
+// merge rather than a restore: importing never clears a flag or a note.
+func (s *HNStore) ImportJob(j ExportJob) (storyCreated bool, outcome importOutcome, err error) {
+	if !validJobStatus(j.Status) {
+		return false, importUnchanged, fmt.Errorf("job %d has an unknown status %d", j.HnId, j.Status)
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return false, importUnchanged, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var storyCount int
+	if err := tx.Get(&storyCount, `SELECT count(*) FROM hiring_story WHERE hn_id=?`, j.StoryHnId); err != nil {
+		return false, importUnchanged, fmt.Errorf("failed to select hiring story: %w", err)
+	}
+	if storyCount == 0 {
+		if j.StoryTitle == "" {
+			return false, importUnchanged, fmt.Errorf("story %d of job %d is not stored and has no title", j.StoryHnId, j.HnId)
+		}
+		_, err := tx.Exec(`INSERT INTO hiring_story (hn_id, title, time, kind) VALUES (?, ?, ?, ?)`,
+			j.StoryHnId, j.StoryTitle, j.StoryTime, j.StoryKind)
+		if err != nil {
+			return false, importUnchanged, fmt.Errorf("failed to create hiring story: %w", err)
+		}
+		storyCreated = true
+	}
+
+	var current struct {
+		StoryId uint64 `db:"hiring_story_hn_id"`
+		Seen    bool   `db:"seen"`
+		Saved   bool   `db:"saved"`
+		Notes   string `db:"notes"`
+	}
+	err = tx.Get(&current, `SELECT hiring_story_hn_id, seen, saved, notes FROM hiring_job WHERE hn_id=?`, j.HnId)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err := tx.Exec(
+			`INSERT INTO hiring_job (hn_id, hiring_story_hn_id, text, time, status, seen, saved, notes, content_hash)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			j.HnId, j.StoryHnId, j.Text, j.Time, j.Status, j.Seen, j.Saved, j.Notes, contentHash(j.Text),
+		)
+		if err != nil {
+			return false, importUnchanged, fmt.Errorf("failed to create hiring job: %w", err)
+		}
+		outcome = importCreated
+	case err != nil:
+		return false, importUnchanged, fmt.Errorf("failed to select hiring job: %w", err)
+	case current.StoryId != j.StoryHnId:
+		return false, importUnchanged, fmt.Errorf("job %d belongs to story %d, not %d", j.HnId, current.StoryId, j.StoryHnId)
+	default:
+		seen := current.Seen || j.Seen
+		saved := current.Saved || j.Saved
+		notes := cmp.Or(current.Notes, j.Notes)
+		if seen == current.Seen && saved == current.Saved && notes == current.Notes {
+			break
+		}
+		_, err := tx.Exec(`UPDATE hiring_job SET seen=?, saved=?, notes=? WHERE hn_id=?`, seen, saved, notes, j.HnId)
+		if err != nil {
+			return false, importUnchanged, fmt.Errorf("failed to update hiring job: %w", err)
+		}
+		outcome = importUpdated
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, importUnchanged, fmt.Errorf("failed to commit imported hiring job: %w", err)
+	}
+
+	return storyCreated, outcome, nil
+}
+
+// GetBackfillSubmissions retrieves the submissions checked by backfills by
+// id.
+func (s *HNStore) GetBackfillSubmissions() (map[uint64]BackfillSubmission, error) {
+	subs := []BackfillSubmission{}
+	query := `SELECT hn_id, thread, kind, title, month, time, completed_at FROM backfill_submission`
+	if err := s.db.Select(&subs, query); err != nil {
+		return nil, fmt.Errorf("failed to select backfill submissions: %w", err)
+	}
+
+	byId := make(map[uint64]BackfillSubmission, len(subs))
+	for _, sub := range subs {
+		byId[sub.HnId] = sub
+	}
+	return byId, nil
+}
+
+// SaveBackfillSubmission saves a submission checked by a backfill.
+func (s *HNStore) SaveBackfillSubmission(sub *BackfillSubmission) error {
+	query := `INSERT OR REPLACE INTO backfill_submission (hn_id, thread, kind, title, month, time, completed_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.Exec(query, sub.HnId, sub.Thread, sub.Kind, sub.Title, sub.Month, sub.Time, sub.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save backfill submission %d: %w", sub.HnId, err)
+	}
+
+	return nil
+}
+
+// CompleteBackfillSubmission marks a hiring story as fully fetched by a
+// backfill.
+func (s *HNStore) CompleteBackfillSubmission(hnId uint64, completedAt int64) error {
+	_, err := s.db.Exec(`UPDATE backfill_submission SET completed_at=? WHERE hn_id=?`, completedAt, hnId)
+	if err != nil {
+		return fmt.Errorf("failed to complete backfill submission %d: %w", hnId, err)
+	}
+
+	return nil
+}
+
+// GetAllJobs retrieves every stored job of every story.
+func (s *HNStore) GetAllJobs() ([]HnJob, error) {
+	jobs := []HnJob{}
+
+	query := `SELECT hn_id, seen, saved, text, time, status
+            FROM hiring_job
+            ORDER BY hn_id DESC`
+	if err := s.db.Select(&jobs, query); err != nil {
+		return nil, fmt.Errorf("failed to select hiring jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// SaveJobHeader stores the structured header fields of a job, replacing any
+// previously saved roles and locations.
+func (s *HNStore) SaveJobHeader(hnJobId uint64, h *JobHeader) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE hiring_job
+            SET company=?, remote=?, onsite=?, hybrid=?, visa=?,
+              salary_min=?, salary_max=?, salary_currency=?
+            WHERE hn_id=?`
+	_, err = tx.Exec(
+		query,
+		h.Company, h.Remote, h.Onsite, h.Hybrid, h.Visa,
+		h.SalaryMin, h.SalaryMax, h.SalaryCurrency,
+		hnJobId,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to set hiring job as seen: %w", err)
+		return fmt.Errorf("failed to update hiring job header: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM hiring_job_role WHERE hiring_job_hn_id=?`, hnJobId); err != nil {
+		return fmt.Errorf("failed to delete hiring job roles: %w", err)
+	}
+	for _, role := range h.Roles {
+		_, err := tx.Exec(`INSERT INTO hiring_job_role (hiring_job_hn_id, role) VALUES (?, ?)`, hnJobId, role)
+		if err != nil {
+			return fmt.Errorf("failed to create hiring job role: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM hiring_job_location WHERE hiring_job_hn_id=?`, hnJobId); err != nil {
+		return fmt.Errorf("failed to delete hiring job locations: %w", err)
+	}
+	for _, location := range h.Locations {
+		_, err := tx.Exec(`INSERT INTO hiring_job_location (hiring_job_hn_id, location) VALUES (?, ?)`, hnJobId, location)
+		if err != nil {
+			return fmt.Errorf("failed to create hiring job location: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit hiring job header: %w", err)
+	}
+
+	return nil
+}
+
+// GetJobHeader retrieves the structured header fields of a job.
+func (s *HNStore) GetJobHeader(hnJobId uint64) (*JobHeader, error) {
+	var row struct {
+		Company        string `db:"company"`
+		Remote         bool   `db:"remote"`
+		Onsite         bool   `db:"onsite"`
+		Hybrid         bool   `db:"hybrid"`
+		Visa           bool   `db:"visa"`
+		SalaryMin      uint64 `db:"salary_min"`
+		SalaryMax      uint64 `db:"salary_max"`
+		SalaryCurrency string `db:"salary_currency"`
+	}
+
+	query := `SELECT company, remote, onsite, hybrid, visa, salary_min, salary_max, salary_currency
+            FROM hiring_job
+            WHERE hn_id=?`
+	if err := s.db.Get(&row, query, hnJobId); err != nil {
+		return nil, fmt.Errorf("failed to select hiring job header: %w", err)
+	}
+
+	h := &JobHeader{
+		Company:        row.Company,
+		Remote:         row.Remote,
+		Onsite:         row.Onsite,
+		Hybrid:         row.Hybrid,
+		Visa:           row.Visa,
+		SalaryMin:      row.SalaryMin,
+		SalaryMax:      row.SalaryMax,
+		SalaryCurrency: row.SalaryCurrency,
+	}
+
+	query = `SELECT role FROM hiring_job_role WHERE hiring_job_hn_id=? ORDER BY id`
+	if err := s.db.Select(&h.Roles, query, hnJobId); err != nil {
+		return nil, fmt.Errorf("failed to select hiring job roles: %w", err)
+	}
+
+	query = `SELECT location FROM hiring_job_location WHERE hiring_job_hn_id=? ORDER BY id`
+	if err := s.db.Select(&h.Locations, query, hnJobId); err != nil {
+		return nil, fmt.Errorf("failed to select hiring job locations: %w", err)
+	}
+
+	return h, nil
+}
+
+// GetJob retrieves a job by its Hacker News id.
+func (s *HNStore) GetJob(hnJobId uint64) (*HnJob, error) {
+	var job HnJob
+
+	query := `SELECT hn_id, seen, saved, text, time, status, edited_at, hiring_story_hn_id, notes
+            FROM hiring_job
+            WHERE hn_id=?`
+	if err := s.db.Get(&job, query, hnJobId); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("failed to select hiring job %d: %w", hnJobId, err)
+	}
+
+	return &job, nil
+}
+
+// GetJobsPage retrieves up to limit OK jobs of a story matching f, newest
+// first, starting after the job with the cursor id. A cursor of 0 starts
+// from the newest job.
+func (s *HNStore) GetJobsPage(hnStoryId, cursor uint64, limit int, f JobFilter) ([]HnJob, error) {
+	jobs := []HnJob{}
+
+	where, args := f.whereClause()
+	query := `SELECT hn_id, seen, saved, text, time, status, edited_at, hiring_story_hn_id, notes
+            FROM hiring_job
+            WHERE hiring_story_hn_id=? and status=? and (?=0 or hn_id < ?)` + where + `
+            ORDER BY hn_id DESC
+            LIMIT ?`
+	args = append([]any{hnStoryId, jobStatusOk, cursor, cursor}, args...)
+	args = append(args, limit)
+	if err := s.db.Select(&jobs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select hiring jobs page: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// UpdateJob applies p to a job. It returns ZeroRowsUpdated if the job
+// doesn't exist.
+func (s *HNStore) UpdateJob(hnJobId uint64, p JobPatch) error {
+	var sets []string
+	var args []any
+	if p.Seen != nil {
+		sets = append(sets, "seen=?")
+		args = append(args, *p.Seen)
+	}
+	if p.Saved != nil {
+		sets = append(sets, "saved=?")
+		args = append(args, *p.Saved)
+	}
+	if p.Notes != nil {
+		sets = append(sets, "notes=?")
+		args = append(args, *p.Notes)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	query := `UPDATE hiring_job SET ` + strings.Join(sets, ", ") + ` WHERE hn_id=?`
+	res, err := s.db.Exec(query, append(args, hnJobId)...)
+	if err != nil {
+		return fmt.Errorf("failed to update hiring job: %w", err)
 	}
 
 	affectedRows, err := res.RowsAffected()
@@ -232,6 +761,242 @@ func (s *HNStore) SetJobAsSeen(hnJobId uint64) error {
 	return nil
 }
 
+// UpdateJobText replaces the text of a job if it changed, keeping the
+// previous version as a revision. It returns true if the job was edited.
+func (s *HNStore) UpdateJobText(hnJobId uint64, text string) (bool, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current struct {
+		Text        string `db:"text"`
+		ContentHash string `db:"content_hash"`
+	}
+	err = tx.Get(&current, `SELECT text, content_hash FROM hiring_job WHERE hn_id=?`, hnJobId)
+	if err != nil {
+		return false, fmt.Errorf("failed to select hiring job text: %w", err)
+	}
+
+	// Jobs saved before hashes were stored have an empty content_hash.
+	if current.ContentHash == "" {
+		current.ContentHash = contentHash(current.Text)
+	}
+
+	hash := contentHash(text)
+	edited := hash != current.ContentHash
+	if edited {
+		now := time.Now().Unix()
+		_, err := tx.Exec(
+			`INSERT INTO hiring_job_revision (hiring_job_hn_id, text, content_hash, time) VALUES (?, ?, ?, ?)`,
+			hnJobId, current.Text, current.ContentHash, now,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to create hiring job revision: %w", err)
+		}
+
+		_, err = tx.Exec(`UPDATE hiring_job SET text=?, content_hash=?, edited_at=? WHERE hn_id=?`, text, hash, now, hnJobId)
+		if err != nil {
+			return false, fmt.Errorf("failed to update hiring job text: %w", err)
+		}
+	} else {
+		_, err := tx.Exec(`UPDATE hiring_job SET content_hash=? WHERE hn_id=?`, hash, hnJobId)
+		if err != nil {
+			return false, fmt.Errorf("failed to update hiring job content hash: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit hiring job text: %w", err)
+	}
+
+	return edited, nil
+}
+
+// GetJobRevisions retrieves the previous versions of a job, newest first.
+func (s *HNStore) GetJobRevisions(hnJobId uint64) ([]HnJobRevision, error) {
+	revisions := []HnJobRevision{}
+
+	query := `SELECT id, hiring_job_hn_id, text, content_hash, time
+            FROM hiring_job_revision
+            WHERE hiring_job_hn_id=?
+            ORDER BY id DESC`
+	if err := s.db.Select(&revisions, query, hnJobId); err != nil {
+		return nil, fmt.Errorf("failed to select hiring job revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// MarkJobsRemoved sets the status of the given OK jobs to removed in a single
+// transaction. It returns the number of jobs updated.
+func (s *HNStore) MarkJobsRemoved(hnJobIds []uint64) (int64, error) {
+	return s.updateJobsStatus(hnJobIds, jobStatusOk, jobStatusRemoved)
+}
+
+// MarkJobsRestored sets the status of the given removed jobs back to OK in a
+// single transaction, e.g. once they are kids of their story again. It
+// returns the number of jobs updated.
+func (s *HNStore) MarkJobsRestored(hnJobIds []uint64) (int64, error) {
+	return s.updateJobsStatus(hnJobIds, jobStatusRemoved, jobStatusOk)
+}
+
+// updateJobsStatus sets the status of the given jobs with status from to
+// status to in a single transaction.
+func (s *HNStore) updateJobsStatus(hnJobIds []uint64, from, to uint8) (int64, error) {
+	if len(hnJobIds) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var updated int64
+	for _, id := range hnJobIds {
+		res, err := tx.Exec(`UPDATE hiring_job SET status=? WHERE hn_id=? and status=?`, to, id, from)
+		if err != nil {
+			return 0, fmt.Errorf("failed to mark hiring job %d as %s: %w", id, jobStatusName(to), err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		updated += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit %s hiring jobs: %w", jobStatusName(to), err)
+	}
+
+	return updated, nil
+}
+
+// GetJobIdsToVerify retrieves the ids of the OK jobs selected by t.
+func (s *HNStore) GetJobIdsToVerify(t VerifyTarget) ([]uint64, error) {
+	ids := []uint64{}
+
+	query := `SELECT j.hn_id
+            FROM hiring_job j
+            JOIN hiring_story s ON s.hn_id = j.hiring_story_hn_id
+            WHERE j.status=?`
+	args := []any{jobStatusOk}
+	switch {
+	case t.Stalest > 0:
+		query += ` ORDER BY j.last_verified_at ASC, j.hn_id DESC LIMIT ?`
+		args = append(args, t.Stalest)
+	case !t.From.IsZero() || !t.To.IsZero():
+		if !t.From.IsZero() {
+			query += ` and s.time >= ?`
+			args = append(args, t.From.Unix())
+		}
+		if !t.To.IsZero() {
+			query += ` and s.time < ?`
+			args = append(args, t.To.AddDate(0, 0, 1).Unix())
+		}
+		query += ` ORDER BY j.hn_id DESC`
+	default:
+		query += ` and j.hiring_story_hn_id=? ORDER BY j.hn_id DESC`
+		args = append(args, t.StoryId)
+	}
+
+	if err := s.db.Select(&ids, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to select hiring job IDs to verify: %w", err)
+	}
+
+	return ids, nil
+}
+
+// SetJobVerified sets when a job was last verified.
+func (s *HNStore) SetJobVerified(hnJobId uint64, verifiedAt int64) error {
+	_, err := s.db.Exec(`UPDATE hiring_job SET last_verified_at=? WHERE hn_id=?`, verifiedAt, hnJobId)
+	if err != nil {
+		return fmt.Errorf("failed to set hiring job verified time: %w", err)
+	}
+
+	return nil
+}
+
+// CreateVerifyRun inserts a new verify run and sets its id.
+func (s *HNStore) CreateVerifyRun(run *VerifyRun) error {
+	res, err := s.db.Exec(`INSERT INTO verify_run (target, started_at) VALUES (?, ?)`, run.Target, run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create verify run: %w", err)
+	}
+
+	run.Id, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get verify run id: %w", err)
+	}
+
+	return nil
+}
+
+// AddVerifyRunJob records that a verify run changed the status of a job.
+func (s *HNStore) AddVerifyRunJob(runId int64, hnJobId uint64, status uint8) error {
+	query := `INSERT INTO verify_run_job (verify_run_id, hiring_job_hn_id, status) VALUES (?, ?, ?)`
+	if _, err := s.db.Exec(query, runId, hnJobId, status); err != nil {
+		return fmt.Errorf("failed to create verify run job: %w", err)
+	}
+
+	return nil
+}
+
+// FinishVerifyRun saves the results of a verify run.
+func (s *HNStore) FinishVerifyRun(run *VerifyRun) error {
+	query := `UPDATE verify_run
+            SET finished_at=?, checked=?, changed=?, edited=?, failed=?, interrupted=?
+            WHERE id=?`
+	_, err := s.db.Exec(
+		query,
+		run.FinishedAt, run.Checked, run.Changed, run.Edited, run.Failed, run.Interrupted,
+		run.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update verify run: %w", err)
+	}
+
+	return nil
+}
+
+// GetVerifyRuns retrieves the latest verify runs, newest first.
+func (s *HNStore) GetVerifyRuns(limit int) ([]VerifyRun, error) {
+	runs := []VerifyRun{}
+
+	query := `SELECT id, target, started_at, finished_at, checked, changed, edited, failed, interrupted
+            FROM verify_run
+            ORDER BY id DESC
+            LIMIT ?`
+	if err := s.db.Select(&runs, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to select verify runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetVerifyRunJobs retrieves the jobs whose status was changed by a verify
+// run.
+func (s *HNStore) GetVerifyRunJobs(runId int64) ([]VerifyRunJob, error) {
+	jobs := []VerifyRunJob{}
+
+	query := `SELECT j.hn_id, r.status, j.saved, s.title as story_title
+            FROM verify_run_job r
+            JOIN hiring_job j ON j.hn_id = r.hiring_job_hn_id
+            JOIN hiring_story s ON s.hn_id = j.hiring_story_hn_id
+            WHERE r.verify_run_id=?
+            ORDER BY j.hn_id DESC`
+	if err := s.db.Select(&jobs, query, runId); err != nil {
+		return nil, fmt.Errorf("failed to select verify run jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// SetJobStatus sets the status of a job. It returns ZeroRowsUpdated if the
+// job doesn't exist.
 func (s *HNStore) SetJobStatus(hnJobId uint64, status uint8) error {
 	res, err := s.db.Exec(`UPDATE hiring_job set status=? where hn_id=?`, status, hnJobId)
 	if err != nil {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -81,6 +82,72 @@ func TestHNStore_GetLatestStory_WithNoStories(t *testing.T) {
 	}
 }
 
+func TestHNStore_GetLatestStoryOfKind(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	stories := []*HnStory{
+		{HnId: 1, Title: "Ask HN: Who is hiring? (September 2026)", Time: 100, Kind: storyKindHiring},
+		{HnId: 2, Title: "Ask HN: Who is hiring? (October 2026)", Time: 200, Kind: storyKindHiring},
+		{HnId: 3, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: 201, Kind: storyKindWantsToBeHired},
+	}
+	for _, story := range stories {
+		if err := store.CreateStory(story); err != nil {
+			t.Fatalf("CreateStory() failed: %v", err)
+		}
+	}
+
+	story, err := store.GetLatestStory()
+	if err != nil {
+		t.Fatalf("GetLatestStory() failed: %v", err)
+	}
+	if *story != *stories[1] {
+		t.Errorf("expected story %+v, got %+v", stories[1], story)
+	}
+
+	story, err = store.GetLatestStoryOfKind(storyKindWantsToBeHired)
+	if err != nil {
+		t.Fatalf("GetLatestStoryOfKind() failed: %v", err)
+	}
+	if *story != *stories[2] {
+		t.Errorf("expected story %+v, got %+v", stories[2], story)
+	}
+
+	if _, err := store.GetLatestStoryOfKind(storyKindFreelancer); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected %v, got %v", sql.ErrNoRows, err)
+	}
+}
+
+func TestHNStore_SetCurrentStory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	stories := []*HnStory{
+		{HnId: 1, Title: "Ask HN: Who is hiring? (October 2026)", Time: 100},
+		{HnId: 2, Title: "Ask HN: Who is hiring? (October 2026)", Time: 200},
+	}
+	for _, story := range stories {
+		if err := store.CreateStory(story); err != nil {
+			t.Fatalf("CreateStory() failed: %v", err)
+		}
+	}
+
+	for _, id := range []uint64{1, 2, 1} {
+		if err := store.SetCurrentStory(storyKindHiring, id); err != nil {
+			t.Fatalf("SetCurrentStory() failed: %v", err)
+		}
+		story, err := store.GetLatestStory()
+		if err != nil {
+			t.Fatalf("GetLatestStory() failed: %v", err)
+		}
+		if story.HnId != id {
+			t.Errorf("expected current story %d, got %d", id, story.HnId)
+		}
+	}
+}
+
 func TestStore_GetJobBeforeID(t *testing.T) {
 	t.Run("has_job_before_current_job", func(t *testing.T) {
 		db := setupTestDB(t)
@@ -99,7 +166,7 @@ func TestStore_GetJobBeforeID(t *testing.T) {
 			t.Fatalf("CreateJob() failed: %v", err)
 		}
 
-		gotJob, err := store.GetJobBeforeID(story.HnId, earliestJob.HnId)
+		gotJob, err := store.GetJobBeforeID(story.HnId, earliestJob.HnId, JobFilter{})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -115,7 +182,7 @@ func TestStore_GetJobBeforeID(t *testing.T) {
 		store := &HNStore{db: db}
 		story, earliestJob := setUpStoryWithJob(t, store)
 
-		gotJob, err := store.GetJobBeforeID(story.HnId, earliestJob.HnId)
+		gotJob, err := store.GetJobBeforeID(story.HnId, earliestJob.HnId, JobFilter{})
 		if err == nil {
 			t.Fatalf("expected error, got nil")
 		}
@@ -146,7 +213,7 @@ func TestStore_GetJobAfterID(t *testing.T) {
 			t.Fatalf("CreateJob() failed: %v", err)
 		}
 
-		gotJob, err := store.GetJobAfterID(story.HnId, latestJob.HnId)
+		gotJob, err := store.GetJobAfterID(story.HnId, latestJob.HnId, JobFilter{})
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -162,7 +229,7 @@ func TestStore_GetJobAfterID(t *testing.T) {
 		store := &HNStore{db: db}
 		story, earliestJob := setUpStoryWithJob(t, store)
 
-		gotJob, err := store.GetJobAfterID(story.HnId, earliestJob.HnId)
+		gotJob, err := store.GetJobAfterID(story.HnId, earliestJob.HnId, JobFilter{})
 		if err == nil {
 			t.Fatalf("expected error, got nil")
 		}
@@ -201,7 +268,7 @@ func TestHnJob_TransformedText(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := tt.job.TransformedText()
+			res := string(tt.job.TransformedText())
 			expected := fmt.Sprintf(tt.expected, tt.job.HnId, time.Unix(nowUnix, 0))
 			if res != expected {
 				t.Errorf("expected %s, got %s", expected, res)
@@ -210,60 +277,558 @@ func TestHnJob_TransformedText(t *testing.T) {
 	}
 }
 
-func TestHnStory_IsInSameMonth(t *testing.T) {
+func TestHNStore_SetJobSaved(t *testing.T) {
+	t.Run("saves_and_unsaves_job", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		store := &HNStore{db: db}
+		_, job := setUpStoryWithJob(t, store)
+
+		if err := store.SetJobSaved(job.HnId); err != nil {
+			t.Fatalf("SetJobSaved() failed: %v", err)
+		}
+		if got := queryTestJobById(t, store, job.HnId); got.Saved != 1 {
+			t.Fatalf("expected saved value to be 1, got: %d", got.Saved)
+		}
+
+		if err := store.UnsetJobSaved(job.HnId); err != nil {
+			t.Fatalf("UnsetJobSaved() failed: %v", err)
+		}
+		if got := queryTestJobById(t, store, job.HnId); got.Saved != 0 {
+			t.Fatalf("expected saved value to be 0, got: %d", got.Saved)
+		}
+	})
+
+	t.Run("unknown_job", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		store := &HNStore{db: db}
+		if err := store.SetJobSaved(2); err != ZeroRowsUpdated {
+			t.Fatalf("expected error %v, got %v", ZeroRowsUpdated, err)
+		}
+	})
+}
+
+func TestHNStore_SetJobUnseen(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	_, job := setUpStoryWithJob(t, store)
+
+	if err := store.SetJobAsSeen(job.HnId); err != nil {
+		t.Fatalf("SetJobAsSeen() failed: %v", err)
+	}
+	if err := store.SetJobUnseen(job.HnId); err != nil {
+		t.Fatalf("SetJobUnseen() failed: %v", err)
+	}
+	if got := queryTestJobById(t, store, job.HnId); got.Seen != 0 {
+		t.Fatalf("expected seen value to be 0, got: %d", got.Seen)
+	}
+
+	if err := store.SetJobUnseen(2); err != ZeroRowsUpdated {
+		t.Fatalf("expected error %v, got %v", ZeroRowsUpdated, err)
+	}
+}
+
+func TestHNStore_SetStoryJobsSeen(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	deadJob := &HnJob{HnId: 2, Text: "dead job", Status: jobStatusDead}
+	if err := store.CreateJob(deadJob, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	updated, err := store.SetStoryJobsSeen(story.HnId)
+	if err != nil {
+		t.Fatalf("SetStoryJobsSeen() failed: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("expected 1 job updated, got %d", updated)
+	}
+	if j := queryTestJobById(t, store, job.HnId); j.Seen != 1 {
+		t.Errorf("expected job to be seen")
+	}
+	if j := queryTestJobById(t, store, deadJob.HnId); j.Seen != 0 {
+		t.Errorf("expected dead job to stay unseen")
+	}
+}
+
+func TestHNStore_GetSavedJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+
+	// a saved job from an older story should still be listed
+	oldStory := &HnStory{HnId: 10, Title: "old story", Time: story.Time - 100}
+	if err := store.CreateStory(oldStory); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+	oldJob := &HnJob{HnId: 11, Text: "old job", Time: oldStory.Time, Status: jobStatusOk}
+	if err := store.CreateJob(oldJob, oldStory.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	for _, id := range []uint64{job.HnId, oldJob.HnId} {
+		if err := store.SetJobSaved(id); err != nil {
+			t.Fatalf("SetJobSaved(%d) failed: %v", id, err)
+		}
+	}
+
+	jobs, err := store.GetSavedJobs()
+	if err != nil {
+		t.Fatalf("GetSavedJobs() failed: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 saved jobs, got %d", len(jobs))
+	}
+	if jobs[0].HnId != oldJob.HnId || jobs[0].StoryTitle != oldStory.Title {
+		t.Fatalf("expected job %d from %q, got %+v", oldJob.HnId, oldStory.Title, jobs[0])
+	}
+	if jobs[1].HnId != job.HnId || jobs[1].StoryHnId != story.HnId {
+		t.Fatalf("expected job %d from story %d, got %+v", job.HnId, story.HnId, jobs[1])
+	}
+}
+
+func TestHNStore_GetStoriesWithStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+
+	jobs := []*HnJob{
+		{HnId: 2, Text: "test job 2", Time: job.Time, Status: jobStatusOk},
+		{HnId: 3, Text: "dead job", Time: job.Time, Status: jobStatusDead},
+	}
+	for _, j := range jobs {
+		if err := store.CreateJob(j, story.HnId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+	if err := store.SetJobAsSeen(job.HnId); err != nil {
+		t.Fatalf("SetJobAsSeen() failed: %v", err)
+	}
+
+	emptyStory := &HnStory{HnId: 10, Title: "empty story", Time: story.Time - 100}
+	if err := store.CreateStory(emptyStory); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+
+	stories, err := store.GetStoriesWithStats()
+	if err != nil {
+		t.Fatalf("GetStoriesWithStats() failed: %v", err)
+	}
+
+	expected := []HnStoryStats{
+		{HnStory: *story, Jobs: 2, Seen: 1},
+		{HnStory: *emptyStory, Jobs: 0, Seen: 0},
+	}
+	if !reflect.DeepEqual(stories, expected) {
+		t.Fatalf("expected stories %+v, got %+v", expected, stories)
+	}
+	if stories[0].Unseen() != 1 {
+		t.Fatalf("expected 1 unseen job, got %d", stories[0].Unseen())
+	}
+}
+
+func TestHNStore_GetStory(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, _ := setUpStoryWithJob(t, store)
+
+	got, err := store.GetStory(story.HnId)
+	if err != nil {
+		t.Fatalf("GetStory() failed: %v", err)
+	}
+	if *got != *story {
+		t.Fatalf("expected story %+v, got %+v", story, got)
+	}
+
+	if _, err := store.GetStory(2); err != sql.ErrNoRows {
+		t.Fatalf("expected error %v, got %v", sql.ErrNoRows, err)
+	}
+}
+
+func TestHNStore_SearchJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	oldStory := &HnStory{HnId: 10, Title: "old story", Time: story.Time - 100}
+	if err := store.CreateStory(oldStory); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+
+	jobs := map[uint64]*HnJob{
+		story.HnId:    {HnId: 2, Text: "Acme | Go engineer | Berlin<p>We write go.", Time: job.Time, Status: jobStatusOk},
+		oldStory.HnId: {HnId: 11, Text: "Initech | Go developer | REMOTE", Time: job.Time, Status: jobStatusOk},
+	}
+	for storyId, j := range jobs {
+		if err := store.CreateJob(j, storyId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+	dead := &HnJob{HnId: 3, Text: "Dead | Go", Time: job.Time, Status: jobStatusDead}
+	if err := store.CreateJob(dead, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	ids := func(results []HnJobSearchResult) []uint64 {
+		var res []uint64
+		for _, r := range results {
+			res = append(res, r.HnId)
+		}
+		return res
+	}
+
+	t.Run("ranks_all_stories", func(t *testing.T) {
+		results, err := store.SearchJobs("go", 0, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if got := ids(results); !reflect.DeepEqual(got, []uint64{2, 11}) {
+			t.Fatalf("expected job ids [2 11], got %v", got)
+		}
+		if results[1].StoryTitle != oldStory.Title {
+			t.Fatalf("expected story title %q, got %q", oldStory.Title, results[1].StoryTitle)
+		}
+		if !strings.Contains(results[0].SnippetText("[", "]"), "[Go]") {
+			t.Fatalf("expected highlighted snippet, got %q", results[0].Snippet)
+		}
+	})
+
+	t.Run("single_story", func(t *testing.T) {
+		results, err := store.SearchJobs("go", oldStory.HnId, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if got := ids(results); !reflect.DeepEqual(got, []uint64{11}) {
+			t.Fatalf("expected job ids [11], got %v", got)
+		}
+	})
+
+	t.Run("limit_and_offset", func(t *testing.T) {
+		results, err := store.SearchJobs("go", 0, 1, 1)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if got := ids(results); !reflect.DeepEqual(got, []uint64{11}) {
+			t.Fatalf("expected job ids [11], got %v", got)
+		}
+	})
+
+	t.Run("index_follows_updates", func(t *testing.T) {
+		if _, err := store.db.Exec(`UPDATE hiring_job SET text=? WHERE hn_id=?`, "Umbrella | Rust", 11); err != nil {
+			t.Fatalf("failed to update job text: %v", err)
+		}
+		results, err := store.SearchJobs("umbrella", 0, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if got := ids(results); !reflect.DeepEqual(got, []uint64{11}) {
+			t.Fatalf("expected job ids [11], got %v", got)
+		}
+		results, err = store.SearchJobs("initech", 0, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("expected no results, got %v", ids(results))
+		}
+	})
+
+	t.Run("empty_query", func(t *testing.T) {
+		results, err := store.SearchJobs(`  "" `, 0, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if len(results) != 0 {
+			t.Fatalf("expected no results, got %v", ids(results))
+		}
+	})
+
+	t.Run("ranks_short_posts_first", func(t *testing.T) {
+		long := &HnJob{
+			HnId:   12,
+			Text:   "Hooli | Kotlin | NYC<p>" + strings.Repeat("We build payments for small shops. ", 30) + "Some Elixir and some Elixir tooling.",
+			Time:   job.Time,
+			Status: jobStatusOk,
+		}
+		short := &HnJob{HnId: 13, Text: "Pied Piper | Elixir | Remote", Time: job.Time - 10, Status: jobStatusOk}
+		for _, j := range []*HnJob{long, short} {
+			if err := store.CreateJob(j, story.HnId); err != nil {
+				t.Fatalf("CreateJob() failed: %v", err)
+			}
+		}
+		results, err := store.SearchJobs("elixir", 0, 10, 0)
+		if err != nil {
+			t.Fatalf("SearchJobs() failed: %v", err)
+		}
+		if got := ids(results); !reflect.DeepEqual(got, []uint64{13, 12}) {
+			t.Fatalf("expected job ids [13 12], got %v", got)
+		}
+	})
+}
+
+func TestHNStore_SaveJobHeader(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	_, job := setUpStoryWithJob(t, store)
+
+	first := &JobHeader{
+		Company:   "Acme",
+		Roles:     []string{"Engineer", "Designer"},
+		Locations: []string{"Berlin"},
+		Remote:    true,
+	}
+	if err := store.SaveJobHeader(job.HnId, first); err != nil {
+		t.Fatalf("SaveJobHeader() failed: %v", err)
+	}
+
+	// saving again replaces the previous header
+	expected := &JobHeader{
+		Company:        "Acme GmbH",
+		Roles:          []string{"Engineer"},
+		Locations:      []string{"Berlin", "Munich"},
+		Hybrid:         true,
+		Visa:           true,
+		SalaryMin:      70000,
+		SalaryMax:      90000,
+		SalaryCurrency: "EUR",
+	}
+	if err := store.SaveJobHeader(job.HnId, expected); err != nil {
+		t.Fatalf("SaveJobHeader() failed: %v", err)
+	}
+
+	got, err := store.GetJobHeader(job.HnId)
+	if err != nil {
+		t.Fatalf("GetJobHeader() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected header %+v, got %+v", expected, got)
+	}
+}
+
+func TestStore_GetJobs_WithFilter(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+
+	texts := map[uint64]string{
+		2: "Acme | Go Engineer | Berlin | REMOTE",
+		3: "Initech | Java Engineer | Berlin | REMOTE",
+		4: "Globex | Go Engineer | Berlin | ONSITE",
+		5: "Hooli | Go Engineer | NYC | REMOTE",
+		6: "Umbrella | Go Developer | Berlin | Remote",
+	}
+	for id, text := range texts {
+		j := &HnJob{HnId: id, Text: text, Time: job.Time, Status: jobStatusOk}
+		if err := store.CreateJob(j, story.HnId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+		if err := store.SaveJobHeader(id, ParseJobHeader(text)); err != nil {
+			t.Fatalf("SaveJobHeader() failed: %v", err)
+		}
+	}
+	if err := store.SetJobAsSeen(6); err != nil {
+		t.Fatalf("SetJobAsSeen() failed: %v", err)
+	}
+
+	filter := JobFilter{Remote: true, Location: "berlin", Keyword: "go"}
+
+	minId, maxId, err := store.GetMinMaxJobIDs(story.HnId, filter)
+	if err != nil {
+		t.Fatalf("GetMinMaxJobIDs() failed: %v", err)
+	}
+	if minId != 2 || maxId != 6 {
+		t.Fatalf("expected min/max 2/6, got %d/%d", minId, maxId)
+	}
+
+	first, err := store.GetFirstJob(story.HnId, filter)
+	if err != nil {
+		t.Fatalf("GetFirstJob() failed: %v", err)
+	}
+	if first.HnId != 6 {
+		t.Fatalf("expected first job 6, got %d", first.HnId)
+	}
+	if first.Status != jobStatusOk {
+		t.Fatalf("expected first job to have status %d, got %d", jobStatusOk, first.Status)
+	}
+
+	next, err := store.GetJobAfterID(story.HnId, first.HnId, filter)
+	if err != nil {
+		t.Fatalf("GetJobAfterID() failed: %v", err)
+	}
+	if next.HnId != 2 {
+		t.Fatalf("expected next job 2, got %d", next.HnId)
+	}
+
+	prev, err := store.GetJobBeforeID(story.HnId, next.HnId, filter)
+	if err != nil {
+		t.Fatalf("GetJobBeforeID() failed: %v", err)
+	}
+	if prev.HnId != 6 {
+		t.Fatalf("expected previous job 6, got %d", prev.HnId)
+	}
+
+	filter.Unseen = true
+	first, err = store.GetFirstJob(story.HnId, filter)
+	if err != nil {
+		t.Fatalf("GetFirstJob() failed: %v", err)
+	}
+	if first.HnId != 2 {
+		t.Fatalf("expected first unseen job 2, got %d", first.HnId)
+	}
+
+	_, err = store.GetFirstJob(story.HnId, JobFilter{Location: "tokyo"})
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected error %v, got %v", sql.ErrNoRows, err)
+	}
+	minId, maxId, err = store.GetMinMaxJobIDs(story.HnId, JobFilter{Location: "tokyo"})
+	if err != nil || minId != 0 || maxId != 0 {
+		t.Fatalf("expected min/max 0/0 without error, got %d/%d %v", minId, maxId, err)
+	}
+}
+
+func TestHNStore_UpdateJobText(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	_, job := setUpStoryWithJob(t, store)
+
+	edited, err := store.UpdateJobText(job.HnId, job.Text)
+	if err != nil {
+		t.Fatalf("UpdateJobText() failed: %v", err)
+	}
+	if edited {
+		t.Fatal("expected unchanged text not to be an edit")
+	}
+
+	newText := "test job 1<p>now hiring rust engineers"
+	edited, err = store.UpdateJobText(job.HnId, newText)
+	if err != nil {
+		t.Fatalf("UpdateJobText() failed: %v", err)
+	}
+	if !edited {
+		t.Fatal("expected changed text to be an edit")
+	}
+
+	updated, err := store.GetJob(job.HnId)
+	if err != nil {
+		t.Fatalf("GetJob() failed: %v", err)
+	}
+	if updated.Text != newText || updated.EditedAt == 0 {
+		t.Errorf("expected job text %q with edited_at set, got %+v", newText, updated)
+	}
+
+	revisions, err := store.GetJobRevisions(job.HnId)
+	if err != nil {
+		t.Fatalf("GetJobRevisions() failed: %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Text != job.Text || revisions[0].ContentHash != contentHash(job.Text) {
+		t.Fatalf("expected one revision with the previous text, got %+v", revisions)
+	}
+
+	results, err := store.SearchJobs("rust", 0, 10, 0)
+	if err != nil {
+		t.Fatalf("SearchJobs() failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected edited text to be searchable, got %d results", len(results))
+	}
+
+	if _, err := store.UpdateJobText(999, "unknown"); err == nil {
+		t.Error("expected an error for an unknown job")
+	}
+}
+
+func TestHNStore_MarkJobsRemoved(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	deadJob := &HnJob{HnId: 2, Text: "dead job", Status: jobStatusDead}
+	if err := store.CreateJob(deadJob, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	updated, err := store.MarkJobsRemoved([]uint64{job.HnId, deadJob.HnId, 999})
+	if err != nil {
+		t.Fatalf("MarkJobsRemoved() failed: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("expected 1 job updated, got %d", updated)
+	}
+
+	if j := queryTestJobById(t, store, job.HnId); j.Status != jobStatusRemoved {
+		t.Errorf("expected status %d, got %d", jobStatusRemoved, j.Status)
+	}
+	if j := queryTestJobById(t, store, deadJob.HnId); j.Status != jobStatusDead {
+		t.Errorf("expected dead job to keep status %d, got %d", jobStatusDead, j.Status)
+	}
+}
+
+func TestHNStore_GetJobIdsToVerify(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	jan := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 2, 12, 0, 0, 0, time.UTC)
+	for _, s := range []HnStory{{HnId: 100, Time: uint64(jan.Unix())}, {HnId: 200, Time: uint64(feb.Unix())}} {
+		if err := store.CreateStory(&s); err != nil {
+			t.Fatalf("CreateStory() failed: %v", err)
+		}
+	}
+	jobs := []struct {
+		id, story uint64
+		status    uint8
+	}{{1, 100, jobStatusOk}, {2, 100, jobStatusDead}, {3, 200, jobStatusOk}, {4, 200, jobStatusOk}}
+	for _, j := range jobs {
+		if err := store.CreateJob(&HnJob{HnId: j.id, Status: j.status}, j.story); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+	if err := store.SetJobVerified(4, 1000); err != nil {
+		t.Fatalf("SetJobVerified() failed: %v", err)
+	}
+
 	tests := []struct {
-		name       string
-		story      *HnStory
-		targetTime time.Time
-		expected   bool
+		name     string
+		target   VerifyTarget
+		expected []uint64
 	}{
-		{
-			name: "same_year_same_month",
-			story: &HnStory{
-				HnId:  1,
-				Title: "test story",
-				Time:  uint64(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix()),
-			},
-			targetTime: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
-			expected:   true,
-		},
-		{
-			name: "same_year_different_month",
-			story: &HnStory{
-				HnId:  1,
-				Title: "test story",
-				Time:  uint64(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC).Unix()),
-			},
-			targetTime: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
-			expected:   false,
-		},
-		{
-			name: "different_year_same_month",
-			story: &HnStory{
-				HnId:  1,
-				Title: "test story",
-				Time:  uint64(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Unix()),
-			},
-			targetTime: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
-			expected:   false,
-		},
-		{
-			name: "different_year_different_month",
-			story: &HnStory{
-				HnId:  1,
-				Title: "test story",
-				Time:  uint64(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC).Unix()),
-			},
-			targetTime: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
-			expected:   false,
-		},
+		{"story", VerifyTarget{StoryId: 100}, []uint64{1}},
+		{"date_range", VerifyTarget{From: jan.Truncate(24 * time.Hour), To: jan.Truncate(24 * time.Hour)}, []uint64{1}},
+		{"open_date_range", VerifyTarget{From: feb.Truncate(24 * time.Hour)}, []uint64{4, 3}},
+		{"stalest", VerifyTarget{Stalest: 2}, []uint64{3, 1}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			res := tt.story.IsInSameMonth(tt.targetTime)
-			if res != tt.expected {
-				t.Errorf("expected %v, got %v", tt.expected, res)
+			ids, err := store.GetJobIdsToVerify(tt.target)
+			if err != nil {
+				t.Fatalf("GetJobIdsToVerify() failed: %v", err)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
 			}
 		})
 	}
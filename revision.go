@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// HnJobRevision is a previous version of a job's text, recorded when an edit
+// was detected.
+type HnJobRevision struct {
+	Id          uint64 `db:"id"`
+	HnJobId     uint64 `db:"hiring_job_hn_id"`
+	Text        string `db:"text"`
+	ContentHash string `db:"content_hash"`
+	// Time is when the edit replacing this version was detected.
+	Time uint64 `db:"time"`
+}
+
+// contentHash returns the hash used to detect edits of a job's text.
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+type diffOp string
+
+const (
+	diffSame    diffOp = "same"
+	diffAdded   diffOp = "added"
+	diffRemoved diffOp = "removed"
+)
+
+// DiffLine is a line of a diff between two versions of a job.
+type DiffLine struct {
+	Op   diffOp
+	Text string
+}
+
+// jobTextLines splits the html text of a job into its non-empty plain text
+// lines.
+func jobTextLines(text string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(renderJobPlain(text), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// diffLines returns the line diff turning a into b, based on their longest
+// common subsequence.
+func diffLines(a, b []string) []DiffLine {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{diffSame, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{diffRemoved, a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{diffAdded, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{diffRemoved, a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{diffAdded, b[j]})
+	}
+
+	return diff
+}
+
+// JobChange is the diff made by one edit of a job.
+type JobChange struct {
+	Time  uint64
+	Lines []DiffLine
+}
+
+// DetectedAt returns when the edit was detected, formatted for display.
+func (c JobChange) DetectedAt() string {
+	return time.Unix(int64(c.Time), 0).UTC().Format("2006-01-02 15:04 UTC")
+}
+
+// jobChanges returns the diff of every edit of a job, newest first, given its
+// current text and its revisions ordered newest first.
+func jobChanges(currentText string, revisions []HnJobRevision) []JobChange {
+	changes := make([]JobChange, 0, len(revisions))
+	newer := currentText
+	for _, rev := range revisions {
+		changes = append(changes, JobChange{
+			Time:  rev.Time,
+			Lines: diffLines(jobTextLines(rev.Text), jobTextLines(newer)),
+		})
+		newer = rev.Text
+	}
+	return changes
+}
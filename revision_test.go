@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJobTextLines(t *testing.T) {
+	text := "Acme | Go Engineer | Remote<p>We use <a href=\"https://acme.com\">Go</a> &amp; SQL.\n<p><p>Apply: jobs&#x40;acme.com"
+	expected := []string{"Acme | Go Engineer | Remote", "We use Go & SQL.", "Apply: jobs@acme.com"}
+
+	if lines := jobTextLines(text); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected %q, got %q", expected, lines)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []string
+		expected []DiffLine
+	}{
+		{
+			name:     "no_changes",
+			a:        []string{"a", "b"},
+			b:        []string{"a", "b"},
+			expected: []DiffLine{{diffSame, "a"}, {diffSame, "b"}},
+		},
+		{
+			name:     "line_changed",
+			a:        []string{"Acme", "Go Engineer", "Apply"},
+			b:        []string{"Acme", "Rust Engineer", "Apply"},
+			expected: []DiffLine{{diffSame, "Acme"}, {diffRemoved, "Go Engineer"}, {diffAdded, "Rust Engineer"}, {diffSame, "Apply"}},
+		},
+		{
+			name:     "lines_added_and_removed",
+			a:        []string{"a", "b", "c"},
+			b:        []string{"b", "c", "d"},
+			expected: []DiffLine{{diffRemoved, "a"}, {diffSame, "b"}, {diffSame, "c"}, {diffAdded, "d"}},
+		},
+		{
+			name:     "from_empty",
+			a:        nil,
+			b:        []string{"a"},
+			expected: []DiffLine{{diffAdded, "a"}},
+		},
+		{
+			name:     "to_empty",
+			a:        []string{"a"},
+			b:        nil,
+			expected: []DiffLine{{diffRemoved, "a"}},
+		},
+		{
+			name:     "both_empty",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := diffLines(tt.a, tt.b); !reflect.DeepEqual(diff, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, diff)
+			}
+		})
+	}
+}
+
+func TestJobChanges(t *testing.T) {
+	revisions := []HnJobRevision{
+		{Text: "Acme<p>Go", Time: 200},
+		{Text: "Acme", Time: 100},
+	}
+
+	changes := jobChanges("Acme<p>Rust", revisions)
+	expected := []JobChange{
+		{Time: 200, Lines: []DiffLine{{diffSame, "Acme"}, {diffRemoved, "Go"}, {diffAdded, "Rust"}}},
+		{Time: 100, Lines: []DiffLine{{diffSame, "Acme"}, {diffAdded, "Go"}}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, changes)
+	}
+}
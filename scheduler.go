@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskStatus is the state of a scheduled task.
+type TaskStatus struct {
+	Name string `json:"name"`
+	// Every is the task interval, e.g. "1h0m0s".
+	Every     string    `json:"every"`
+	Running   bool      `json:"running"`
+	Runs      uint64    `json:"runs"`
+	LastStart time.Time `json:"last_start,omitzero"`
+	LastEnd   time.Time `json:"last_end,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+type scheduledTask struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+	// later skips the run at startup.
+	later bool
+}
+
+// Scheduler runs tasks in the background on fixed intervals. Tasks share a
+// single lock so they never overlap: a task due while another one is running
+// waits for it to finish.
+type Scheduler struct {
+	tasks []scheduledTask
+	// lock holds a value while a task runs. It is a channel so waiting for
+	// it can be cancelled.
+	lock chan struct{}
+
+	mu       sync.Mutex
+	statuses map[string]*TaskStatus
+}
+
+// NewScheduler creates a Scheduler without tasks.
+func NewScheduler() *Scheduler {
+	return &Scheduler{lock: make(chan struct{}, 1), statuses: map[string]*TaskStatus{}}
+}
+
+// Every adds a task running every interval. It must be called before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.tasks = append(s.tasks, scheduledTask{name: name, interval: interval, run: run})
+	s.statuses[name] = &TaskStatus{Name: name, Every: interval.String()}
+}
+
+// EveryLater adds a task like Every, except that Start doesn't run it right
+// away, e.g. because it just ran.
+func (s *Scheduler) EveryLater(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.Every(name, interval, run)
+	s.tasks[len(s.tasks)-1].later = true
+}
+
+// Start runs every task not added with EveryLater once right away, one after
+// another in the order they were added, then every task on its interval, until ctx is done. The returned
+// func waits for running tasks to stop.
+func (s *Scheduler) Start(ctx context.Context) (wait func()) {
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for _, task := range s.tasks {
+			if ctx.Err() != nil {
+				return
+			}
+			if task.later {
+				continue
+			}
+			s.runTask(ctx, task)
+		}
+
+		for _, task := range s.tasks {
+			wg.Go(func() {
+				ticker := time.NewTicker(task.interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ticker.C:
+						s.runTask(ctx, task)
+					case <-ctx.Done():
+						return
+					}
+				}
+			})
+		}
+	})
+	return wg.Wait
+}
+
+// runTask runs task once no other task holds the lock, unless ctx is done
+// first.
+func (s *Scheduler) runTask(ctx context.Context, task scheduledTask) {
+	select {
+	case s.lock <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-s.lock }()
+
+	log.Printf("starting scheduled %s", task.name)
+	s.update(task.name, func(st *TaskStatus) {
+		st.Running = true
+		st.LastStart = time.Now()
+	})
+
+	err := task.run(ctx)
+	if err != nil {
+		log.Printf("scheduled %s failed: %v", task.name, err)
+	}
+
+	s.update(task.name, func(st *TaskStatus) {
+		st.Running = false
+		st.Runs++
+		st.LastEnd = time.Now()
+		st.LastError = ""
+		if err != nil {
+			st.LastError = err.Error()
+		}
+	})
+}
+
+func (s *Scheduler) update(name string, f func(st *TaskStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s.statuses[name])
+}
+
+// Status returns a copy of the status of every task, sorted by name.
+func (s *Scheduler) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		statuses = append(statuses, *st)
+	}
+	slices.SortFunc(statuses, func(a, b TaskStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return statuses
+}
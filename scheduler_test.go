@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	t.Run("runs_tasks_on_interval", func(t *testing.T) {
+		s := NewScheduler()
+		var runs atomic.Int64
+		s.Every("sync", 10*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := s.Start(ctx)
+		time.Sleep(55 * time.Millisecond)
+		cancel()
+		wait()
+
+		if n := runs.Load(); n < 3 {
+			t.Errorf("expected at least 3 runs, got %d", n)
+		}
+		status := s.Status()
+		if len(status) != 1 || status[0].Name != "sync" || status[0].Runs != uint64(runs.Load()) || status[0].Running {
+			t.Errorf("unexpected status %+v", status)
+		}
+	})
+
+	t.Run("runs_later_tasks_after_interval", func(t *testing.T) {
+		s := NewScheduler()
+		var runs atomic.Int64
+		s.EveryLater("sync", 30*time.Millisecond, func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := s.Start(ctx)
+		time.Sleep(15 * time.Millisecond)
+		if n := runs.Load(); n != 0 {
+			t.Errorf("expected no run before the interval, got %d", n)
+		}
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+		wait()
+
+		if n := runs.Load(); n != 1 {
+			t.Errorf("expected 1 run after the interval, got %d", n)
+		}
+	})
+
+	t.Run("stops_waiting_when_cancelled", func(t *testing.T) {
+		s := NewScheduler()
+		started := make(chan struct{})
+		s.Every("sync", time.Hour, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		s.Every("verify", time.Hour, func(ctx context.Context) error {
+			t.Error("expected verify not to run after cancel")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := s.Start(ctx)
+		<-started
+		cancel()
+		wait()
+	})
+
+	t.Run("does_not_overlap_tasks", func(t *testing.T) {
+		s := NewScheduler()
+		var running, peak atomic.Int64
+		var mu sync.Mutex
+		var order []string
+		task := func(name string) func(ctx context.Context) error {
+			return func(ctx context.Context) error {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+				n := running.Add(1)
+				defer running.Add(-1)
+				if n > peak.Load() {
+					peak.Store(n)
+				}
+				time.Sleep(15 * time.Millisecond)
+				return nil
+			}
+		}
+		s.Every("sync", 5*time.Millisecond, task("sync"))
+		s.Every("verify", 5*time.Millisecond, task("verify"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := s.Start(ctx)
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+		wait()
+
+		if p := peak.Load(); p != 1 {
+			t.Errorf("expected tasks to never overlap, peak was %d", p)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(order) < 3 || order[0] != "sync" || order[1] != "verify" {
+			t.Errorf("expected the tasks to run in order at startup, then wait for each other, got %v", order)
+		}
+		for _, st := range s.Status() {
+			if st.Runs < 2 {
+				t.Errorf("expected %s to run again instead of being skipped, got %d runs", st.Name, st.Runs)
+			}
+		}
+	})
+
+	t.Run("records_last_error", func(t *testing.T) {
+		s := NewScheduler()
+		s.Every("verify", time.Hour, func(ctx context.Context) error {
+			return errors.New("api unavailable")
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wait := s.Start(ctx)
+		deadline := time.Now().Add(time.Second)
+		for s.Status()[0].Runs == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		wait()
+
+		if st := s.Status()[0]; st.LastError != "api unavailable" || st.LastEnd.IsZero() {
+			t.Errorf("unexpected status %+v", st)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// Snippets returned by SearchJobs wrap matched terms with these markers so
+// they can be highlighted after the job html has been stripped.
+const (
+	snippetMatchStart = "\x02"
+	snippetMatchEnd   = "\x03"
+)
+
+var (
+	htmlTagRegexp        = regexp.MustCompile(`<[^>]*>`)
+	partialHtmlTagRegexp = regexp.MustCompile(`<[^>]*$`)
+)
+
+// HnJobSearchResult is an HnJob matching a search query.
+type HnJobSearchResult struct {
+	HnJob
+	StoryHnId  uint64 `db:"story_hn_id"`
+	StoryTitle string `db:"story_title"`
+	Snippet    string `db:"snippet"`
+}
+
+// SnippetHTML returns the escaped snippet with matches wrapped in <mark> tags.
+func (r HnJobSearchResult) SnippetHTML() template.HTML {
+	return template.HTML(highlightSnippet(r.Snippet, html.EscapeString, "<mark>", "</mark>"))
+}
+
+// SnippetText returns the plain text snippet with matches wrapped in start
+// and end.
+func (r HnJobSearchResult) SnippetText(start, end string) string {
+	return highlightSnippet(r.Snippet, func(s string) string { return s }, start, end)
+}
+
+// highlightSnippet strips html from a job text snippet and replaces the match
+// markers with start and end.
+func highlightSnippet(snippet string, escape func(string) string, start, end string) string {
+	text := htmlTagRegexp.ReplaceAllString(snippet, " ")
+	text = partialHtmlTagRegexp.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+	text = escape(text)
+	text = strings.ReplaceAll(text, snippetMatchStart, start)
+	return strings.ReplaceAll(text, snippetMatchEnd, end)
+}
+
+// ftsQuery converts user input into a full-text query where every word must
+// match. Words are quoted so characters like "-" or ":" are not treated as
+// query syntax. A trailing "*" is kept for prefix searches.
+func ftsQuery(input string) string {
+	var terms []string
+	for word := range strings.FieldsSeq(input) {
+		word = strings.ReplaceAll(word, `"`, "")
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.TrimRight(word, "*")
+		if word == "" {
+			continue
+		}
+		if prefix {
+			word = word + "*"
+		}
+		terms = append(terms, `"`+word+`"`)
+	}
+
+	return strings.Join(terms, " ")
+}
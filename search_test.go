@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestFtsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "single_word", input: "golang", expected: `"golang"`},
+		{name: "multiple_words", input: "  go   berlin ", expected: `"go" "berlin"`},
+		{name: "query_syntax", input: `c++ -remote NEAR "sre`, expected: `"c++" "-remote" "NEAR" "sre"`},
+		{name: "prefix", input: "engin*", expected: `"engin*"`},
+		{name: "only_syntax", input: `* "" **`, expected: ""},
+		{name: "empty", input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := ftsQuery(tt.input)
+			if res != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, res)
+			}
+		})
+	}
+}
+
+func TestHnJobSearchResult_Snippet(t *testing.T) {
+	tests := []struct {
+		name         string
+		snippet      string
+		expectedHTML string
+		expectedText string
+	}{
+		{
+			name:         "plain_text",
+			snippet:      "Acme | \x02Go\x03 engineer",
+			expectedHTML: "Acme | <mark>Go</mark> engineer",
+			expectedText: "Acme | [Go] engineer",
+		},
+		{
+			name:         "strips_html",
+			snippet:      "Berlin<p>We use <i>\x02go\x03</i> &amp; rust <a href=\"https:&#x2F;&#x2F;ex",
+			expectedHTML: "Berlin We use <mark>go</mark> &amp; rust",
+			expectedText: "Berlin We use [go] & rust",
+		},
+		{
+			name:         "escapes_text",
+			snippet:      "&lt;script&gt; \x02go\x03",
+			expectedHTML: "&lt;script&gt; <mark>go</mark>",
+			expectedText: "<script> [go]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := HnJobSearchResult{Snippet: tt.snippet}
+			if res := string(r.SnippetHTML()); res != tt.expectedHTML {
+				t.Errorf("expected html %q, got %q", tt.expectedHTML, res)
+			}
+			if res := r.SnippetText("[", "]"); res != tt.expectedText {
+				t.Errorf("expected text %q, got %q", tt.expectedText, res)
+			}
+		})
+	}
+}
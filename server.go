@@ -1,21 +1,47 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"html/template"
 	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
-	"text/template"
+	"sync"
+	"time"
 )
 
+const searchPageSize = 20
+
+// defaultStateTTL is how long the cached latest story and job ID range are
+// used before being reloaded, so syncs run by other processes show up.
+const defaultStateTTL = 30 * time.Second
+
+// shutdownTimeout is how long in-flight requests get to finish when the
+// server is stopped.
+const shutdownTimeout = 5 * time.Second
+
 type Server struct {
-	store    *HNStore
-	hnStory  *HnStory
-	minJobId uint64
-	maxJobId uint64
+	store *HNStore
+	// scheduler runs the background tasks reported by /api/status, if any.
+	scheduler *Scheduler
+
+	// mu guards the cached latest story and its job ID range.
+	mu          sync.RWMutex
+	hnStory     *HnStory
+	minJobId    uint64
+	maxJobId    uint64
+	refreshedAt time.Time
+	// ttl is how long the cached state is used, 0 to never expire it.
+	ttl time.Duration
+	// refreshing is held while the expired cached state is reloaded.
+	refreshing sync.Mutex
 }
 
 // NewServer creates a new Server.
@@ -25,10 +51,12 @@ func NewServer(
 	minJobId, maxJobId uint64,
 ) *Server {
 	return &Server{
-		store:    store,
-		hnStory:  latestStory,
-		minJobId: minJobId,
-		maxJobId: maxJobId,
+		store:       store,
+		hnStory:     latestStory,
+		minJobId:    minJobId,
+		maxJobId:    maxJobId,
+		refreshedAt: time.Now(),
+		ttl:         defaultStateTTL,
 	}
 }
 
@@ -42,7 +70,7 @@ func InitializeNewServer(store *HNStore) (*Server, error) {
 		return nil, fmt.Errorf("failed to get latest hiring story: %w", err)
 	}
 
-	minJobId, maxJobId, err := store.GetMinMaxJobIDs(latestStory.HnId)
+	minJobId, maxJobId, err := store.GetMinMaxJobIDs(latestStory.HnId, JobFilter{})
 	if err != nil {
 		return nil, fmt.Errorf("GetMinMaxJobsIds(%d) failed: %w", latestStory.HnId, err)
 	}
@@ -50,19 +78,103 @@ func InitializeNewServer(store *HNStore) (*Server, error) {
 	return NewServer(store, latestStory, minJobId, maxJobId), nil
 }
 
+// Refresh reloads the cached latest story and its job ID range, e.g. after a
+// sync added jobs or a new story.
+func (s *Server) Refresh() error {
+	latestStory, err := s.store.GetLatestStory()
+	if err != nil {
+		return fmt.Errorf("failed to get latest hiring story: %w", err)
+	}
+
+	minJobId, maxJobId, err := s.store.GetMinMaxJobIDs(latestStory.HnId, JobFilter{})
+	if err != nil {
+		return fmt.Errorf("GetMinMaxJobsIds(%d) failed: %w", latestStory.HnId, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hnStory == nil || s.hnStory.HnId != latestStory.HnId {
+		log.Printf("serving latest story %d", latestStory.HnId)
+	}
+	s.hnStory = latestStory
+	s.minJobId = minJobId
+	s.maxJobId = maxJobId
+	s.refreshedAt = time.Now()
+
+	return nil
+}
+
+// latest returns the cached latest story and its job ID range, reloading
+// them first if they expired. While one request reloads them, others keep
+// using the expired values.
+func (s *Server) latest() (*HnStory, uint64, uint64) {
+	s.mu.RLock()
+	expired := s.ttl > 0 && time.Since(s.refreshedAt) > s.ttl
+	s.mu.RUnlock()
+
+	if expired && s.refreshing.TryLock() {
+		if err := s.Refresh(); err != nil {
+			log.Println("failed to refresh latest story:", err)
+		}
+		s.refreshing.Unlock()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hnStory, s.minJobId, s.maxJobId
+}
+
 // GetMux creates a new serve mux and registers its handler funcs.
 func (s *Server) GetMux() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /", s.indexHandler)
-	mux.HandleFunc("GET /api/seen/{hnId}", s.seenHandler)
+	mux.HandleFunc("GET /stories", s.storiesHandler)
+	mux.HandleFunc("GET /story/{storyId}", s.storyHandler)
+	mux.HandleFunc("GET /latest/{kind}", s.latestStoryHandler)
+	mux.HandleFunc("GET /saved", s.savedJobsHandler)
+	mux.HandleFunc("GET /search", s.searchHandler)
+	mux.HandleFunc("GET /job/{hnId}/history", s.jobHistoryHandler)
+	mux.HandleFunc("GET /export", s.exportHandler)
+	mux.HandleFunc("GET /api/status", s.statusHandler)
+	mux.HandleFunc("POST /api/seen/{hnId}", s.seenHandler)
+	mux.HandleFunc("DELETE /api/seen/{hnId}", s.seenHandler)
+	mux.HandleFunc("POST /api/story/{storyId}/seen", s.storySeenHandler)
+	mux.HandleFunc("POST /api/saved/{hnId}", s.savedHandler)
+	mux.HandleFunc("DELETE /api/saved/{hnId}", s.savedHandler)
+	s.registerAPIRoutes(mux)
 	return mux
 }
 
-// Run starts the web server.
-func (s *Server) Run() {
-	mux := s.GetMux()
-	fmt.Println("Listening on http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", mux))
+// Run starts the web server on addr and shuts it down gracefully once ctx
+// is done.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     s.GetMux(),
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	host, port, _ := net.SplitHostPort(addr)
+	if host == "" {
+		host = "localhost"
+	}
+	fmt.Printf("Listening on http://%s\n", net.JoinHostPort(host, port))
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("shutting down server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
 }
 
 // parseUint64OrDefault parses stringVal as a uint64, returning defaultVal if
@@ -88,39 +200,173 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the cached job ID range only applies to unfiltered jobs
+	filter := JobFilterFromQuery(r.URL.Query())
+	story, minJobId, maxJobId := s.latest()
+	if !filter.IsEmpty() {
+		var err error
+		minJobId, maxJobId, err = s.store.GetMinMaxJobIDs(story.HnId, filter)
+		if err != nil {
+			log.Printf("GetMinMaxJobsIds(%d) failed: %v", story.HnId, err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	s.renderJobPage(w, r, story, filter, minJobId, maxJobId)
+}
+
+// statusHandler returns the latest story served and the status of the
+// scheduled tasks as JSON.
+func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	story, minJobId, maxJobId := s.latest()
+
+	data := struct {
+		Story    *HnStory     `json:"story"`
+		MinJobId uint64       `json:"min_job_id"`
+		MaxJobId uint64       `json:"max_job_id"`
+		Tasks    []TaskStatus `json:"tasks"`
+	}{
+		Story:    story,
+		MinJobId: minJobId,
+		MaxJobId: maxJobId,
+		Tasks:    []TaskStatus{},
+	}
+	if s.scheduler != nil {
+		data.Tasks = s.scheduler.Status()
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// storiesHandler renders all hiring stories with their job counts.
+func (s *Server) storiesHandler(w http.ResponseWriter, r *http.Request) {
+	stories, err := s.store.GetStoriesWithStats()
+	if err != nil {
+		log.Println("failed to select hiring stories:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(template.ParseFiles("templates/stories.html"))
+	if err := tmpl.Execute(w, stories); err != nil {
+		log.Println("failed to execute to templates", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// storyHandler renders the jobs of any stored hiring story. Unlike the index
+// page, the job ID range is looked up on every request.
+func (s *Server) storyHandler(w http.ResponseWriter, r *http.Request) {
+	pathValue := r.PathValue("storyId")
+	storyId, err := strconv.ParseUint(pathValue, 10, 64)
+	if err != nil {
+		log.Printf("failed to convert path value:%q to uint64", pathValue)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	story, err := s.store.GetStory(storyId)
+	if err != nil {
+		log.Printf("GetStory(%d) failed: %v", storyId, err)
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	filter := JobFilterFromQuery(r.URL.Query())
+	minJobId, maxJobId, err := s.store.GetMinMaxJobIDs(story.HnId, filter)
+	if err != nil {
+		log.Printf("GetMinMaxJobsIds(%d) failed: %v", story.HnId, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	s.renderJobPage(w, r, story, filter, minJobId, maxJobId)
+}
+
+// latestStoryHandler redirects to the jobs of the latest story of a kind,
+// e.g. /latest/wants-to-be-hired. The latest "Who is hiring?" story is
+// served by the index page.
+func (s *Server) latestStoryHandler(w http.ResponseWriter, r *http.Request) {
+	kind, err := parseStoryKind(r.PathValue("kind"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if kind == storyKindHiring {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	story, err := s.store.GetLatestStoryOfKind(kind)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, fmt.Sprintf("No %q story has been synced.", kind.Label()), http.StatusNotFound)
+			return
+		}
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, fmt.Sprintf("/story/%d", story.HnId), http.StatusFound)
+}
+
+// renderJobPage renders a single job of story matching filter, selected by the
+// after/before query params or directly by the job query param. When no job
+// matches the filter, the page is rendered without a job.
+func (s *Server) renderJobPage(
+	w http.ResponseWriter,
+	r *http.Request,
+	story *HnStory,
+	filter JobFilter,
+	minJobId, maxJobId uint64,
+) {
 	after := s.parseUint64OrDefault(r.URL.Query().Get("after"), 0)
 	before := s.parseUint64OrDefault(r.URL.Query().Get("before"), 0)
+	jobId := s.parseUint64OrDefault(r.URL.Query().Get("job"), 0)
 
 	var hj *HnJob
 	var err error
-	if after == 0 && before > 0 {
-		hj, err = s.store.GetJobBeforeID(s.hnStory.HnId, before)
+	isFirstJob := false
+	if jobId > 0 {
+		hj, err = s.store.GetJobAfterID(story.HnId, jobId+1, filter)
+	} else if after == 0 && before > 0 {
+		hj, err = s.store.GetJobBeforeID(story.HnId, before, filter)
 	} else if after > 0 && before == 0 {
-		hj, err = s.store.GetJobAfterID(s.hnStory.HnId, after)
+		hj, err = s.store.GetJobAfterID(story.HnId, after, filter)
 	} else {
-		hj, err = s.store.GetFirstJob(s.hnStory.HnId)
+		isFirstJob = true
+		hj, err = s.store.GetFirstJob(story.HnId, filter)
 	}
-	if err != nil {
+	if err != nil && !(isFirstJob && !filter.IsEmpty() && errors.Is(err, sql.ErrNoRows)) {
 		log.Println("failed to select hiring job:", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
 
-	hj.Text = hj.TransformedText()
 	data := struct {
-		Story    *HnStory
-		Job      *HnJob
-		MinJobId uint64
-		MaxJobId uint64
+		Story       *HnStory
+		Job         *HnJob
+		MinJobId    uint64
+		MaxJobId    uint64
+		Filter      JobFilter
+		FilterQuery template.URL
+		Kinds       []StoryKind
 	}{
-		Story:    s.hnStory,
-		Job:      hj,
-		MinJobId: s.minJobId,
-		MaxJobId: s.maxJobId,
+		Story:       story,
+		Job:         hj,
+		MinJobId:    minJobId,
+		MaxJobId:    maxJobId,
+		Filter:      filter,
+		FilterQuery: template.URL(filter.Query()),
+		Kinds:       storyKinds,
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	tmpl := template.Must(template.ParseFiles("templates/base.html"))
+	tmpl := template.Must(template.ParseFiles("templates/base.html", "templates/saved_toggle.html", "templates/seen_toggle.html"))
 	if err := tmpl.Execute(w, data); err != nil {
 		log.Println("failed to execute to templates", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -128,6 +374,8 @@ func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// seenHandler marks a job as seen on POST and as unseen on DELETE, then
+// responds with the updated seen toggle.
 func (s *Server) seenHandler(w http.ResponseWriter, r *http.Request) {
 	pathValue := r.PathValue("hnId")
 	hnId, err := strconv.ParseUint(pathValue, 10, 64)
@@ -137,11 +385,252 @@ func (s *Server) seenHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.store.SetJobAsSeen(hnId); err != nil {
+	job := &HnJob{HnId: hnId}
+	if r.Method == http.MethodDelete {
+		err = s.store.SetJobUnseen(hnId)
+	} else {
+		job.Seen = 1
+		err = s.store.SetJobAsSeen(hnId)
+	}
+	if err != nil {
 		if errors.Is(err, ZeroRowsUpdated) {
-			log.Printf("SetJobAsSeen(%d) did not updates any rows", hnId)
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			log.Printf("setting job %d seen value did not update any rows", hnId)
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(template.ParseFiles("templates/seen_toggle.html"))
+	if err := tmpl.ExecuteTemplate(w, "seenToggle", job); err != nil {
+		log.Println("failed to execute to templates", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// storySeenHandler marks every job of a story as seen and makes htmx reload
+// the page.
+func (s *Server) storySeenHandler(w http.ResponseWriter, r *http.Request) {
+	pathValue := r.PathValue("storyId")
+	storyId, err := strconv.ParseUint(pathValue, 10, 64)
+	if err != nil {
+		log.Printf("failed to convert path value:%q to uint64", pathValue)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.store.GetStory(storyId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 			return
 		}
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	n, err := s.store.SetStoryJobsSeen(storyId)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("marked %d jobs of story %d as seen", n, storyId)
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// savedJobsHandler renders every saved job across all hiring stories.
+func (s *Server) savedJobsHandler(w http.ResponseWriter, r *http.Request) {
+	jobs, err := s.store.GetSavedJobs()
+	if err != nil {
+		log.Println("failed to select saved hiring jobs:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(template.ParseFiles("templates/saved.html", "templates/saved_toggle.html"))
+	if err := tmpl.Execute(w, jobs); err != nil {
+		log.Println("failed to execute to templates", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// exportHandler returns the jobs of a story as a file download. The story,
+// format and filters are read from the same query params as the export
+// command flags, defaulting to every job of the latest story as JSON Lines.
+func (s *Server) exportHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format, err := parseExportFormat(cmp.Or(q.Get("format"), string(exportJSONLFormat)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := ExportFilterFromQuery(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	story, _, _ := s.latest()
+	if storyId := s.parseUint64OrDefault(q.Get("story"), 0); storyId > 0 {
+		story, err = s.store.GetStory(storyId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+				return
+			}
+			log.Println("failed to select hiring story:", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	jobs, err := s.store.GetExportJobs(story.HnId, filter)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeExport(&buf, format, story, jobs); err != nil {
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="whoishiring-%d.%s"`, story.HnId, format))
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Println("failed to write export:", err)
+	}
+}
+
+// jobHistoryHandler renders the edits made to a job.
+func (s *Server) jobHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	hnId, err := strconv.ParseUint(r.PathValue("hnId"), 10, 64)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.store.GetJob(hnId)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println("failed to select hiring job:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	revisions, err := s.store.GetJobRevisions(hnId)
+	if err != nil {
+		log.Println("failed to select hiring job revisions:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Job     *HnJob
+		Changes []JobChange
+	}{
+		Job:     job,
+		Changes: jobChanges(job.Text, revisions),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(template.ParseFiles("templates/history.html"))
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Println("failed to execute to templates", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// searchHandler renders ranked job posts matching the q query param.
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	storyId := s.parseUint64OrDefault(r.URL.Query().Get("story"), 0)
+	page := max(s.parseUint64OrDefault(r.URL.Query().Get("page"), 1), 1)
+
+	results, err := s.store.SearchJobs(query, storyId, searchPageSize, int(page-1)*searchPageSize)
+	if err != nil {
+		log.Println("failed to search hiring jobs:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Query    string
+		StoryId  uint64
+		Page     uint64
+		HasMore  bool
+		Results  []HnJobSearchResult
+		NextPage uint64
+		PrevPage uint64
+	}{
+		Query:    query,
+		StoryId:  storyId,
+		Page:     page,
+		HasMore:  len(results) == searchPageSize,
+		Results:  results,
+		NextPage: page + 1,
+		PrevPage: page - 1,
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(template.ParseFiles("templates/search.html"))
+	if err := tmpl.Execute(w, data); err != nil {
+		log.Println("failed to execute to templates", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// savedHandler saves a job on POST and unsaves it on DELETE, then responds
+// with the updated saved toggle.
+func (s *Server) savedHandler(w http.ResponseWriter, r *http.Request) {
+	pathValue := r.PathValue("hnId")
+	hnId, err := strconv.ParseUint(pathValue, 10, 64)
+	if err != nil {
+		log.Printf("failed to convert path value:%q to uint64", pathValue)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	job := &HnJob{HnId: hnId}
+	if r.Method == http.MethodDelete {
+		err = s.store.UnsetJobSaved(hnId)
+	} else {
+		job.Saved = 1
+		err = s.store.SetJobSaved(hnId)
+	}
+	if err != nil {
+		if errors.Is(err, ZeroRowsUpdated) {
+			log.Printf("saving job %d did not update any rows", hnId)
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Println(err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	tmpl := template.Must(template.ParseFiles("templates/saved_toggle.html"))
+	if err := tmpl.ExecuteTemplate(w, "savedToggle", job); err != nil {
+		log.Println("failed to execute to templates", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
 }
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestInitializeNewServer(t *testing.T) {
@@ -75,13 +80,16 @@ func TestServer_seenHandler_request(t *testing.T) {
 
 		mux := s.GetMux()
 		url := fmt.Sprintf("/api/seen/%d", job.HnId)
-		req := httptest.NewRequest("GET", url, nil)
+		req := httptest.NewRequest("POST", url, nil)
 		rr := httptest.NewRecorder()
 		mux.ServeHTTP(rr, req)
 
 		if rr.Code != http.StatusOK {
 			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
 		}
+		if !strings.Contains(rr.Body.String(), "Mark unseen") {
+			t.Fatalf("expected mark unseen toggle, got: %s", rr.Body.String())
+		}
 
 		updatedJob := queryTestJobById(t, store, job.HnId)
 		if updatedJob.HnId != job.HnId {
@@ -90,12 +98,42 @@ func TestServer_seenHandler_request(t *testing.T) {
 		if updatedJob.Seen != 1 {
 			t.Fatalf("expected seen value to be 1, got: %d", updatedJob.Seen)
 		}
+
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("DELETE", url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "Mark seen") {
+			t.Fatalf("expected mark seen toggle, got: %s", rr.Body.String())
+		}
+		if updatedJob := queryTestJobById(t, store, job.HnId); updatedJob.Seen != 0 {
+			t.Fatalf("expected seen value to be 0, got: %d", updatedJob.Seen)
+		}
+	})
+
+	t.Run("get_does_not_change_state", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		store := &HNStore{db: db}
+		_, job := setUpStoryWithJob(t, store)
+
+		s := &Server{store: store}
+		rr := httptest.NewRecorder()
+		s.GetMux().ServeHTTP(rr, httptest.NewRequest("GET", fmt.Sprintf("/api/seen/%d", job.HnId), nil))
+		if rr.Code == http.StatusOK {
+			t.Fatalf("expected GET to be rejected, got: %d", rr.Code)
+		}
+		if updatedJob := queryTestJobById(t, store, job.HnId); updatedJob.Seen != 0 {
+			t.Fatalf("expected seen value to be 0, got: %d", updatedJob.Seen)
+		}
 	})
 
 	t.Run("invalid_path_param", func(t *testing.T) {
 		s := &Server{}
 		mux := s.GetMux()
-		req := httptest.NewRequest("GET", "/api/seen/invalid", nil)
+		req := httptest.NewRequest("POST", "/api/seen/invalid", nil)
 		rr := httptest.NewRecorder()
 		mux.ServeHTTP(rr, req)
 		if rr.Code != http.StatusBadRequest {
@@ -110,11 +148,586 @@ func TestServer_seenHandler_request(t *testing.T) {
 		store := &HNStore{db: db}
 		s := &Server{store: store}
 		mux := s.GetMux()
-		req := httptest.NewRequest("GET", "/api/seen/2", nil)
+		req := httptest.NewRequest("POST", "/api/seen/2", nil)
 		rr := httptest.NewRecorder()
 		mux.ServeHTTP(rr, req)
-		if rr.Code != http.StatusBadRequest {
-			t.Fatalf("expected status code %d, got: %d", http.StatusBadRequest, rr.Code)
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status code %d, got: %d", http.StatusNotFound, rr.Code)
+		}
+	})
+
+	t.Run("database_error", func(t *testing.T) {
+		db := setupTestDB(t)
+		store := &HNStore{db: db}
+		db.Close()
+
+		s := &Server{store: store}
+		rr := httptest.NewRecorder()
+		s.GetMux().ServeHTTP(rr, httptest.NewRequest("POST", "/api/seen/1", nil))
+		if rr.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status code %d, got: %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
+
+func TestServer_storySeenHandler_request(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	if err := store.CreateJob(&HnJob{HnId: 2, Text: "test job 2", Status: jobStatusOk}, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	s := &Server{store: store}
+	mux := s.GetMux()
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", fmt.Sprintf("/api/story/%d/seen", story.HnId), nil))
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status code %d, got: %d", http.StatusNoContent, rr.Code)
+	}
+	if rr.Header().Get("HX-Refresh") != "true" {
+		t.Error("expected htmx to refresh the page")
+	}
+	for _, id := range []uint64{job.HnId, 2} {
+		if updatedJob := queryTestJobById(t, store, id); updatedJob.Seen != 1 {
+			t.Errorf("expected job %d to be seen", id)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", "/api/story/999/seen", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status code %d, got: %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestServer_savedHandler_request(t *testing.T) {
+	t.Run("saves_and_unsaves_job", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		store := &HNStore{db: db}
+		_, job := setUpStoryWithJob(t, store)
+
+		s := &Server{store: store}
+		mux := s.GetMux()
+		url := fmt.Sprintf("/api/saved/%d", job.HnId)
+
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("POST", url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "hx-delete") {
+			t.Fatalf("expected unsave toggle, got: %s", rr.Body.String())
+		}
+		if updatedJob := queryTestJobById(t, store, job.HnId); updatedJob.Saved != 1 {
+			t.Fatalf("expected saved value to be 1, got: %d", updatedJob.Saved)
+		}
+
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("DELETE", url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "hx-post") {
+			t.Fatalf("expected save toggle, got: %s", rr.Body.String())
+		}
+		if updatedJob := queryTestJobById(t, store, job.HnId); updatedJob.Saved != 0 {
+			t.Fatalf("expected saved value to be 0, got: %d", updatedJob.Saved)
+		}
+	})
+
+	t.Run("invalid_job_id", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		store := &HNStore{db: db}
+		s := &Server{store: store}
+		mux := s.GetMux()
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("POST", "/api/saved/2", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status code %d, got: %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestServer_savedJobsHandler_request(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	if err := store.SetJobSaved(job.HnId); err != nil {
+		t.Fatalf("SetJobSaved() failed: %v", err)
+	}
+
+	s := &Server{store: store}
+	mux := s.GetMux()
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/saved", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+	}
+	for _, want := range []string{story.Title, job.Text} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Fatalf("expected body to contain %q", want)
+		}
+	}
+}
+
+func TestServer_storyHandler_request(t *testing.T) {
+	t.Run("renders_older_story", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		store := &HNStore{db: db}
+		oldStory, oldJob := setUpStoryWithJob(t, store)
+		latestStory := &HnStory{HnId: 10, Title: "latest story", Time: oldStory.Time + 100}
+		if err := store.CreateStory(latestStory); err != nil {
+			t.Fatalf("CreateStory() failed: %v", err)
+		}
+		newerJob := &HnJob{HnId: 2, Text: "test job 2", Time: oldJob.Time, Status: jobStatusOk}
+		if err := store.CreateJob(newerJob, oldStory.HnId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+
+		s := &Server{store: store, hnStory: latestStory}
+		mux := s.GetMux()
+
+		url := fmt.Sprintf("/story/%d?after=%d", oldStory.HnId, newerJob.HnId)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, oldStory.Title) || !strings.Contains(body, oldJob.Text) {
+			t.Fatalf("expected body to contain story %q and job %q", oldStory.Title, oldJob.Text)
+		}
+		if !strings.Contains(body, fmt.Sprintf("?before=%d", oldJob.HnId)) {
+			t.Fatal("expected previous link to be enabled")
+		}
+	})
+
+	t.Run("unknown_story", func(t *testing.T) {
+		db := setupTestDB(t)
+		defer db.Close()
+
+		s := &Server{store: &HNStore{db: db}}
+		rr := httptest.NewRecorder()
+		s.GetMux().ServeHTTP(rr, httptest.NewRequest("GET", "/story/2", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status code %d, got: %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestServer_latestStoryHandler(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, _ := setUpStoryWithJob(t, store)
+	hired := &HnStory{HnId: 20, Title: "Ask HN: Who wants to be hired? (October 2026)", Time: story.Time, Kind: storyKindWantsToBeHired}
+	if err := store.CreateStory(hired); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+	if err := store.CreateJob(&HnJob{HnId: 21, Text: "Location: Berlin", Time: story.Time, Status: jobStatusOk}, hired.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	s := &Server{store: store, hnStory: story}
+	mux := s.GetMux()
+
+	tests := []struct {
+		url      string
+		code     int
+		location string
+	}{
+		{"/latest/hiring", http.StatusFound, "/"},
+		{"/latest/wants-to-be-hired", http.StatusFound, "/story/20"},
+		{"/latest/freelancer", http.StatusNotFound, ""},
+		{"/latest/interns", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", tt.url, nil))
+		if rr.Code != tt.code {
+			t.Errorf("%s: expected status code %d, got: %d", tt.url, tt.code, rr.Code)
+		}
+		if loc := rr.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s: expected location %q, got %q", tt.url, tt.location, loc)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/story/20", nil))
+	body := rr.Body.String()
+	if !strings.Contains(body, `<span class="font-semibold">Who wants to be hired?</span>`) ||
+		!strings.Contains(body, `<a href="/latest/hiring">Who is hiring?</a>`) {
+		t.Errorf("expected kind tabs with the current kind selected, got: %s", body)
+	}
+}
+
+func TestServer_storiesHandler_request(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, _ := setUpStoryWithJob(t, store)
+
+	s := &Server{store: store}
+	rr := httptest.NewRecorder()
+	s.GetMux().ServeHTTP(rr, httptest.NewRequest("GET", "/stories", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), fmt.Sprintf(`href="/story/%d"`, story.HnId)) {
+		t.Fatalf("expected link to story %d, got: %s", story.HnId, rr.Body.String())
+	}
+}
+
+func TestServer_searchHandler_request(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+
+	s := &Server{store: store}
+	rr := httptest.NewRecorder()
+	s.GetMux().ServeHTTP(rr, httptest.NewRequest("GET", "/search?q=job", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "test <mark>job</mark> 1") {
+		t.Fatalf("expected highlighted match, got: %s", body)
+	}
+	if !strings.Contains(body, fmt.Sprintf("/story/%d?job=%d", story.HnId, job.HnId)) {
+		t.Fatalf("expected link to job %d, got: %s", job.HnId, body)
+	}
+}
+
+func TestServer_indexHandler_filter(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	_, job := setUpStoryWithJob(t, store)
+	for id, text := range map[uint64]string{2: "Acme | Go Engineer | REMOTE", 3: "Initech | Go Engineer | ONSITE", 4: "Globex | Go Engineer | REMOTE"} {
+		j := &HnJob{HnId: id, Text: text, Time: job.Time, Status: jobStatusOk}
+		if err := store.CreateJob(j, 1); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+		if err := store.SaveJobHeader(id, ParseJobHeader(text)); err != nil {
+			t.Fatalf("SaveJobHeader() failed: %v", err)
+		}
+	}
+
+	server, err := InitializeNewServer(store)
+	if err != nil {
+		t.Fatalf("InitializeNewServer() failed: %v", err)
+	}
+	mux := server.GetMux()
+
+	t.Run("carries_filter_through_links", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/?remote=1&keyword=go", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "Globex") {
+			t.Fatalf("expected first remote job, got: %s", body)
+		}
+		if !strings.Contains(body, `href="?after=4&keyword=go&amp;remote=1"`) {
+			t.Fatalf("expected next link with filter, got: %s", body)
+		}
+
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/?after=4&keyword=go&remote=1", nil))
+		body = rr.Body.String()
+		if !strings.Contains(body, "Acme") {
+			t.Fatalf("expected next remote job to skip onsite job, got: %s", body)
+		}
+		if !strings.Contains(body, `<button disabled class="inline-block bg-slate-900 p-1 w-20 text-center disabled:opacity-50">Next</button>`) {
+			t.Fatalf("expected next button to be disabled for the last filtered job, got: %s", body)
 		}
 	})
+
+	t.Run("no_matching_jobs", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/?location=tokyo", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), "No jobs match these filters.") {
+			t.Fatalf("expected no jobs message, got: %s", rr.Body.String())
+		}
+	})
+}
+
+func TestServer_indexHandler_escapesJobText(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story := &HnStory{HnId: 1, Title: "Ask HN: Who is hiring? <b>(October 2026)</b>", Time: uint64(time.Now().Unix())}
+	if err := store.CreateStory(story); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+	job := &HnJob{
+		HnId:   1,
+		Text:   `Acme<script>alert(1)</script><p><a href="javascript:alert(2)">apply</a> <a href="https://acme.com">site</a><img src=x onerror=alert(3)>`,
+		Time:   story.Time,
+		Status: jobStatusOk,
+	}
+	if err := store.CreateJob(job, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	server, err := InitializeNewServer(store)
+	if err != nil {
+		t.Fatalf("InitializeNewServer() failed: %v", err)
+	}
+
+	mux := server.GetMux()
+	for _, url := range []string{"/", `/?keyword="><script>alert(4)</script>`} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", url, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+
+		body := rr.Body.String()
+		for _, unsafe := range []string{"alert(1)", "javascript:", "onerror", "<b>", "<script>alert(4)"} {
+			if strings.Contains(body, unsafe) {
+				t.Errorf("%s: expected %q to be removed or escaped, got: %s", url, unsafe, body)
+			}
+		}
+		if url == "/" && !strings.Contains(body, `<a href="https://acme.com" rel="nofollow noopener">site</a>`) {
+			t.Errorf("expected safe link, got: %s", body)
+		}
+	}
+}
+
+func TestServer_exportHandler(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	if err := store.CreateJob(&HnJob{HnId: 2, Text: "Acme | Go", Status: jobStatusOk}, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+	if err := store.SetJobSaved(2); err != nil {
+		t.Fatalf("SetJobSaved() failed: %v", err)
+	}
+
+	server, err := InitializeNewServer(store)
+	if err != nil {
+		t.Fatalf("InitializeNewServer() failed: %v", err)
+	}
+	mux := server.GetMux()
+
+	t.Run("latest_story_as_jsonl", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/export", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/jsonl") {
+			t.Errorf("expected jsonl content type, got %q", ct)
+		}
+		if lines := strings.Count(rr.Body.String(), "\n"); lines != 2 {
+			t.Errorf("expected 2 jobs, got %d", lines)
+		}
+	})
+
+	t.Run("saved_jobs_as_markdown", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", fmt.Sprintf("/export?story=%d&format=md&saved=1", story.HnId), nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		expected := fmt.Sprintf(`attachment; filename="whoishiring-%d.md"`, story.HnId)
+		if cd := rr.Header().Get("Content-Disposition"); cd != expected {
+			t.Errorf("expected content disposition %q, got %q", expected, cd)
+		}
+		body := rr.Body.String()
+		if !strings.Contains(body, "## Acme | Go") || strings.Contains(body, fmt.Sprintf("id=%d)", job.HnId)) {
+			t.Errorf("expected only the saved job, got: %s", body)
+		}
+	})
+
+	t.Run("invalid_params", func(t *testing.T) {
+		for _, url := range []string{"/export?format=xml", "/export?status=gone", "/export?seen=maybe"} {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest("GET", url, nil))
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status code %d, got: %d", url, http.StatusBadRequest, rr.Code)
+			}
+		}
+	})
+
+	t.Run("unknown_story", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest("GET", "/export?story=999", nil))
+		if rr.Code != http.StatusNotFound {
+			t.Fatalf("expected status code %d, got: %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
+func TestServer_jobHistoryHandler(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	if _, err := store.UpdateJobText(job.HnId, "test job 1<p>Senior <b>Go</b> engineer"); err != nil {
+		t.Fatalf("UpdateJobText() failed: %v", err)
+	}
+
+	s := &Server{store: store, hnStory: story}
+	mux := s.GetMux()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", fmt.Sprintf("/job/%d/history", job.HnId), nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+	}
+	if body := rr.Body.String(); !strings.Contains(body, "+ Senior Go engineer") {
+		t.Errorf("expected body to contain the added line, got %s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(rr.Body.String(), fmt.Sprintf("/job/%d/history", job.HnId)) {
+		t.Error("expected job page to link to the job history")
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/job/999/history", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d, got: %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestServer_statusHandler(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	server, err := InitializeNewServer(store)
+	if err != nil {
+		t.Fatalf("InitializeNewServer() failed: %v", err)
+	}
+	server.scheduler = NewScheduler()
+	server.scheduler.Every("sync", time.Hour, func(context.Context) error { return nil })
+
+	// A newer story and job are added, e.g. by a background sync.
+	newStory := &HnStory{HnId: 20, Title: "new story", Time: story.Time + 100}
+	if err := store.CreateStory(newStory); err != nil {
+		t.Fatalf("CreateStory() failed: %v", err)
+	}
+	if err := store.CreateJob(&HnJob{HnId: 21, Text: "new job", Status: jobStatusOk}, newStory.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	getStatus := func() map[string]any {
+		rr := httptest.NewRecorder()
+		server.GetMux().ServeHTTP(rr, httptest.NewRequest("GET", "/api/status", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got: %d", http.StatusOK, rr.Code)
+		}
+		var status map[string]any
+		if err := json.NewDecoder(rr.Body).Decode(&status); err != nil {
+			t.Fatalf("failed to decode status: %v", err)
+		}
+		return status
+	}
+
+	status := getStatus()
+	if id := status["story"].(map[string]any)["hn_id"]; id != float64(story.HnId) || status["max_job_id"] != float64(job.HnId) {
+		t.Errorf("expected cached story %d, got %v", story.HnId, status)
+	}
+	if tasks := status["tasks"].([]any); len(tasks) != 1 || tasks[0].(map[string]any)["name"] != "sync" {
+		t.Errorf("expected sync task status, got %v", status["tasks"])
+	}
+
+	if err := server.Refresh(); err != nil {
+		t.Fatalf("Refresh() failed: %v", err)
+	}
+	status = getStatus()
+	if id := status["story"].(map[string]any)["hn_id"]; id != float64(newStory.HnId) || status["max_job_id"] != float64(21) {
+		t.Errorf("expected refreshed story %d, got %v", newStory.HnId, status)
+	}
+}
+
+func TestServer_refreshesAfterSync(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	server, err := InitializeNewServer(store)
+	if err != nil {
+		t.Fatalf("InitializeNewServer() failed: %v", err)
+	}
+	server.ttl = 10 * time.Millisecond
+
+	live := httptest.NewServer(server.GetMux())
+	defer live.Close()
+
+	get := func(path string) string {
+		resp, err := http.Get(live.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var body strings.Builder
+		if _, err := io.Copy(&body, resp.Body); err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		return body.String()
+	}
+
+	previousLink := fmt.Sprintf("?before=%d", job.HnId)
+	if strings.Contains(get(fmt.Sprintf("/?job=%d", job.HnId)), previousLink) {
+		t.Fatal("expected previous link to be disabled before sync")
+	}
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user/whoishiring.json":
+			json.NewEncoder(w).Encode(map[string]any{"submitted": []uint64{story.HnId, 998, 999}})
+		case fmt.Sprintf("/item/%d.json", story.HnId):
+			json.NewEncoder(w).Encode(ApiStory{Id: story.HnId, Title: story.Title, Time: story.Time, Kids: []uint64{2, job.HnId}})
+		case "/item/2.json":
+			json.NewEncoder(w).Encode(ApiJob{Id: 2, Text: "newly synced job", Time: story.Time})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	client := NewClient(api.URL)
+	if err := NewSyncProcess(store, client, NewFetcher(client, 1, 0)).Run(context.Background()); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	time.Sleep(2 * server.ttl)
+
+	if !strings.Contains(get(fmt.Sprintf("/?job=%d", job.HnId)), previousLink) {
+		t.Error("expected previous link to be enabled after sync")
+	}
+	if body := get("/"); !strings.Contains(body, "newly synced job") || !strings.Contains(body, "?after=2") {
+		t.Errorf("expected the synced job with an enabled next link, got %s", body)
+	}
 }
@@ -1,101 +1,142 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"slices"
 	"sync"
-	"time"
+	"sync/atomic"
 )
 
 type SyncProcess struct {
-	store  *HNStore
-	client *Client
+	store   *HNStore
+	client  *Client
+	fetcher *Fetcher
+	// window is how many of the latest whoishiring submissions Run searches
+	// for the current stories.
+	window int
 }
 
-func NewSyncProcess(store *HNStore, client *Client) *SyncProcess {
+func NewSyncProcess(store *HNStore, client *Client, fetcher *Fetcher) *SyncProcess {
 	return &SyncProcess{
-		store:  store,
-		client: client,
+		store:   store,
+		client:  client,
+		fetcher: fetcher,
+		window:  defaultStoryWindow,
 	}
 }
 
-// Run will fetch and save the latest "Who is Hiring?" story and jobs.
-func (s *SyncProcess) Run() error {
+// Run will fetch and save the latest story of every kind and their jobs.
+// Missing "Who wants to be hired?" and "Freelancer?" stories are skipped,
+// since they aren't posted every month. Jobs that failed to be added are
+// returned as one error once every story was synced, and are tried again by
+// the next sync. It stops when ctx is cancelled, keeping the jobs saved so
+// far.
+func (s *SyncProcess) Run(ctx context.Context) error {
 	log.Println("starting data sync...")
 
-	storyID, err := s.getLatestStoryID()
+	submissionIds, err := s.client.GetWhoIsHiringSubmissionIds(ctx)
 	if err != nil {
 		return err
 	}
 
-	if err := s.getNewJobs(storyID); err != nil {
+	detected, err := s.detectStories(ctx, submissionIds)
+	if err != nil {
 		return err
 	}
 
-	return nil
-}
+	var jobErrs []error
+	for _, kind := range storyKinds {
+		var found *detectedStory
+		if d, ok := detected[kind]; ok {
+			found = &d
+		}
 
-// getLatestStoryID will return the latest "Who is Hiring?" story ID
-func (s *SyncProcess) getLatestStoryID() (uint64, error) {
-	submissionIds, err := s.client.GetWhoIsHiringSubmissionIds()
-	if err != nil {
-		return 0, err
+		storyID, err := s.getLatestStoryID(kind, found)
+		if errors.Is(err, ErrStoryNotFound) && kind != storyKindHiring {
+			log.Printf("skipping %q stories: %v", kind.Label(), err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.store.SetCurrentStory(kind, storyID); err != nil {
+			return err
+		}
+
+		if err := s.getNewJobs(ctx, storyID); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			jobErrs = append(jobErrs, err)
+		}
 	}
 
-	// Hacker News will usually post 2 new stories at the beginning of the month:
-	// "Who is hiring?", and "Who wants to be hired?". There used to be a post
-	// for freelancers but it was removed. Not sure if they will bring it back.
-	// https://news.ycombinator.com/item?id=46167746
-	// An assumption is being made that the current "Who is hiring?" story is one
-	// of the first 3 submission IDs.
-	maxSubmissions := 3
-	submissionsToSearch := submissionIds[0:maxSubmissions]
+	return errors.Join(jobErrs...)
+}
 
-	existingStory, err := s.store.GetLatestStory()
-	if err != nil && err != sql.ErrNoRows {
+// getLatestStoryID will return the ID of the latest story of kind, creating
+// it if found is a story that isn't stored yet. found is the latest story of
+// kind detected among the whoishiring submissions, nil if there is none.
+//
+// The stored story is kept when found is missing or older, e.g. when the
+// story of the new month hasn't been posted yet.
+func (s *SyncProcess) getLatestStoryID(kind StoryKind, found *detectedStory) (uint64, error) {
+	existingStory, err := s.store.GetLatestStoryOfKind(kind)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return 0, err
 	}
 
-	if existingStory != nil &&
-		existingStory.IsInSameMonth(time.Now()) &&
-		slices.Contains(submissionsToSearch, existingStory.HnId) {
-		log.Printf("found existing 'Who is Hiring?' story: %d", existingStory.HnId)
+	if found == nil {
+		if existingStory == nil {
+			return 0, fmt.Errorf("no %q story found in the latest %d submissions: %w", kind.Label(), s.window, ErrStoryNotFound)
+		}
+		log.Printf("no %q story found, using existing story: %d", kind.Label(), existingStory.HnId)
 		return existingStory.HnId, nil
 	}
 
-	newStory, err := s.client.FindWhoIsHiringStory(submissionsToSearch)
-	if err != nil {
-		return 0, err
+	if existingStory != nil {
+		if existingStory.HnId == found.Id {
+			log.Printf("found existing %q story: %d (%s)", kind.Label(), found.Id, found.Month)
+			return found.Id, nil
+		}
+		if month := storyMonth(existingStory.Title, existingStory.Time); month > found.Month {
+			log.Printf("new story hasn't been posted. using existing %q story: %d (%s)", kind.Label(), existingStory.HnId, month)
+			return existingStory.HnId, nil
+		}
 	}
 
-	// latest story fetched is still the saved existing story??
-	// this will most likely be true if we sync before the latest story has been
-	// posted. Or if there has been some other unkown issue. New stories aren't usually
-	// posted on the weekends.
-	if newStory.Id == existingStory.HnId {
-		log.Printf("new story hasn't been posted. using existing 'Whois is Hiring?' story: %d", existingStory.HnId)
-		return existingStory.HnId, nil
+	// found may already be stored without being the latest story of kind,
+	// e.g. a re-post saved by a backfill.
+	if _, err := s.store.GetStory(found.Id); err == nil {
+		return found.Id, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
 	}
 
 	if err := s.store.CreateStory(&HnStory{
-		HnId:  newStory.Id,
-		Title: newStory.Title,
-		Time:  newStory.Time,
+		HnId:  found.Id,
+		Title: found.Title,
+		Time:  found.Time,
+		Kind:  kind,
 	}); err != nil {
 		return 0, err
 	}
 
-	log.Println("new 'Who is Hiring?' story found and created")
-	return newStory.Id, nil
+	log.Printf("new %q story found and created: %d (%s)", kind.Label(), found.Id, found.Month)
+	return found.Id, nil
 }
 
-// getNewJobs will fetch and save new jobs for a given hiring story.
-func (s *SyncProcess) getNewJobs(hnStoryId uint64) error {
-	log.Printf("process jobs for 'Who is Hiring?' story id %d", hnStoryId)
+// getNewJobs will fetch and save new jobs for a given hiring story. Jobs
+// that fail to be fetched or saved don't stop the others, and are returned
+// as one error once every job was tried.
+func (s *SyncProcess) getNewJobs(ctx context.Context, hnStoryId uint64) error {
+	log.Printf("process jobs for story id %d", hnStoryId)
 
-	hs, err := s.client.GetStory(hnStoryId)
+	hs, err := s.client.GetStory(ctx, hnStoryId)
 	if err != nil {
 		return fmt.Errorf("failed to get story %d: %w", hnStoryId, err)
 	}
@@ -105,44 +146,109 @@ func (s *SyncProcess) getNewJobs(hnStoryId uint64) error {
 		return fmt.Errorf("failed to GetJobIdsByStoryId: %w", err)
 	}
 
-	var wg sync.WaitGroup
+	newIds, removedIds, restoredIds, unchanged := reconcileJobIds(savedIds, hs.Kids)
+	// A dead or deleted story, or one that suddenly has no kids, says nothing
+	// about its jobs. Removing them all would only restore them next sync.
+	if len(savedIds) > 0 && (hs.Dead || hs.Deleted || len(hs.Kids) == 0) {
+		log.Printf("story %d is dead, deleted or has no kids, not removing its jobs", hnStoryId)
+		unchanged += len(removedIds)
+		removedIds = nil
+	}
+
+	removed, err := s.store.MarkJobsRemoved(removedIds)
+	if err != nil {
+		return err
+	}
+	restored, err := s.store.MarkJobsRestored(restoredIds)
+	if err != nil {
+		return err
+	}
 
 	// Save new job posts
-	for _, jobId := range hs.Kids {
-		if _, ok := savedIds[jobId]; ok {
-			continue
+	var added atomic.Int64
+	var mu sync.Mutex
+	var jobErrs []error
+	failed := func(err error) {
+		log.Println(err)
+		mu.Lock()
+		defer mu.Unlock()
+		jobErrs = append(jobErrs, err)
+	}
+	err = s.fetcher.FetchJobs(ctx, newIds, func(id uint64, job *ApiJob, err error) {
+		if errors.Is(err, ErrNullItem) || errors.Is(err, ErrNotFound) {
+			log.Printf("skipping job %d, it doesn't exist", id)
+			return
+		}
+		if err != nil {
+			failed(fmt.Errorf("failed to get job %d: %w", id, err))
+			return
 		}
 
+		err = s.store.CreateJob(&HnJob{
+			HnId:   job.Id,
+			Text:   job.Text,
+			Time:   job.Time,
+			Status: job.StatusToDbValue(),
+		}, hnStoryId)
+		if err != nil {
+			failed(fmt.Errorf("failed to create job %d: %w", id, err))
+			return
+		}
+
+		if err := s.store.SaveJobHeader(job.Id, ParseJobHeader(job.Text)); err != nil {
+			log.Printf("failed to save job header %d: %v", id, err)
+		}
+
+		added.Add(1)
+		log.Printf("added new hiring job %d", id)
+	})
+	if err != nil {
+		return fmt.Errorf("sync interrupted after adding %d of %d new jobs: %w", added.Load(), len(newIds), err)
+	}
+
+	log.Printf("story %d: %d jobs added, %d removed, %d restored, %d unchanged",
+		hnStoryId, added.Load(), removed, restored, unchanged)
+	if len(jobErrs) > 0 {
+		return fmt.Errorf("failed to add %d of %d new jobs of story %d: %w",
+			len(jobErrs), len(newIds), hnStoryId, errors.Join(jobErrs...))
+	}
+	return nil
+}
+
+// reconcileJobIds compares the saved job ids of a story and their status
+// with its current kids. It returns the ids to add, the saved ids that are no
+// longer kids, the removed ids that are kids again and the number of other
+// saved ids that still are.
+func reconcileJobIds(savedIds map[uint64]uint8, kids []uint64) ([]uint64, []uint64, []uint64, int) {
+	var newIds []uint64
+	current := make(map[uint64]bool, len(kids))
+	for _, jobId := range kids {
 		// How is this possible, you ask??
 		if jobId < 1 {
 			log.Printf("Skipping hiring job id: %d\n", jobId)
 			continue
 		}
 
-		wg.Add(1)
-		go func(id uint64) {
-			defer wg.Done()
-			job, err := s.client.GetJob(id)
-			if err != nil {
-				log.Printf("failed to get job %d: %v", id, err)
-				return
-			}
-
-			err = s.store.CreateJob(&HnJob{
-				HnId:   job.Id,
-				Text:   job.Text,
-				Time:   job.Time,
-				Status: job.StatusToDbValue(),
-			}, hnStoryId)
-			if err != nil {
-				log.Printf("failed to create job %d: %v", id, err)
-				return
-			}
+		current[jobId] = true
+		if _, ok := savedIds[jobId]; !ok {
+			newIds = append(newIds, jobId)
+		}
+	}
 
-			log.Printf("added new hiring job %d", id)
-		}(jobId)
+	var removedIds, restoredIds []uint64
+	unchanged := 0
+	for jobId, status := range savedIds {
+		switch {
+		case !current[jobId]:
+			removedIds = append(removedIds, jobId)
+		case status == jobStatusRemoved:
+			restoredIds = append(restoredIds, jobId)
+		default:
+			unchanged++
+		}
 	}
+	slices.Sort(removedIds)
+	slices.Sort(restoredIds)
 
-	wg.Wait()
-	return nil
+	return newIds, removedIds, restoredIds, unchanged
 }
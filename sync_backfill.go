@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+)
+
+const (
+	// monthLayout formats the month of a story, e.g. "2020-01".
+	monthLayout = "2006-01"
+	// backfillSlack keeps the stories posted a few days before the month
+	// they are for when a backfill stops walking older submissions.
+	backfillSlack = 7 * 24 * time.Hour
+)
+
+var titleMonthRegexp = regexp.MustCompile(`(?i)\((January|February|March|April|May|June|July|August|September|October|November|December)\s+(\d{4})\)`)
+
+// BackfillSubmission is a submission of the whoishiring user checked by a
+// backfill. It is kept as a checkpoint so interrupted backfills don't fetch
+// it again, and completed once all jobs of a thread were fetched.
+type BackfillSubmission struct {
+	HnId uint64 `db:"hn_id"`
+	// Thread is true if the submission is a monthly thread of Kind.
+	Thread bool      `db:"thread"`
+	Kind   StoryKind `db:"kind"`
+	Title  string    `db:"title"`
+	// Month is the month of a thread, formatted with monthLayout.
+	Month       string `db:"month"`
+	Time        uint64 `db:"time"`
+	CompletedAt *int64 `db:"completed_at"`
+}
+
+// storyMonth returns the month a story is for, formatted with monthLayout.
+// It is read from titles like "Ask HN: Who is hiring? (January 2020)", and
+// falls back to the month the story was posted in.
+func storyMonth(title string, postedAt uint64) string {
+	if m := titleMonthRegexp.FindStringSubmatch(title); m != nil {
+		if t, err := time.Parse("January 2006", m[1]+" "+m[2]); err == nil {
+			return t.Format(monthLayout)
+		}
+	}
+	return time.Unix(int64(postedAt), 0).UTC().Format(monthLayout)
+}
+
+// Backfill fetches every story of every kind for since's month or later,
+// along with their jobs. A zero since backfills every story. Submissions
+// already checked and stories already completed by previous backfills are
+// skipped, so an interrupted backfill continues where it left off.
+func (s *SyncProcess) Backfill(ctx context.Context, since time.Time) error {
+	log.Println("starting backfill...")
+
+	submissionIds, err := s.client.GetWhoIsHiringSubmissionIds(ctx)
+	if err != nil {
+		return err
+	}
+
+	checked, err := s.store.GetBackfillSubmissions()
+	if err != nil {
+		return err
+	}
+
+	sinceMonth := ""
+	if !since.IsZero() {
+		sinceMonth = since.Format(monthLayout)
+	}
+
+	// Submissions are listed newest first, so the walk stops at the first
+	// one posted before since.
+	var stories []BackfillSubmission
+	fetched := 0
+	for _, id := range submissionIds {
+		sub, ok := checked[id]
+		if !ok {
+			story, err := s.client.GetStory(ctx, id)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fmt.Errorf("backfill interrupted after checking %d submissions: %w", fetched, ctxErr)
+			}
+			switch {
+			case errors.Is(err, ErrNullItem) || errors.Is(err, ErrNotFound):
+				log.Printf("skipping submission %d, it doesn't exist", id)
+				story = &ApiStory{Id: id}
+			case err != nil:
+				return err
+			}
+
+			sub = BackfillSubmission{HnId: id, Time: story.Time}
+			if kind, ok := story.Kind(); ok {
+				sub.Thread = true
+				sub.Kind = kind
+				sub.Title = story.Title
+				sub.Month = storyMonth(story.Title, story.Time)
+			}
+			if err := s.store.SaveBackfillSubmission(&sub); err != nil {
+				return err
+			}
+			fetched++
+		}
+
+		// deleted submissions are saved without a time
+		if sub.Time == 0 {
+			continue
+		}
+		if !since.IsZero() && time.Unix(int64(sub.Time), 0).Before(since.Add(-backfillSlack)) {
+			break
+		}
+		if sub.Thread && sub.Month >= sinceMonth {
+			stories = append(stories, sub)
+		}
+	}
+	log.Printf("found %d stories, fetched %d new submissions", len(stories), fetched)
+
+	// Stories with jobs that failed to be added aren't completed, so the
+	// next backfill tries them again.
+	completed := 0
+	var jobErrs []error
+	for i, sub := range stories {
+		if sub.CompletedAt != nil {
+			completed++
+			continue
+		}
+
+		if err := s.ensureStory(sub); err != nil {
+			return err
+		}
+		if err := s.getNewJobs(ctx, sub.HnId); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("backfill interrupted at story %d (%s), %d of %d stories done: %w",
+					sub.HnId, sub.Month, i, len(stories), err)
+			}
+			jobErrs = append(jobErrs, err)
+			continue
+		}
+		if err := s.store.CompleteBackfillSubmission(sub.HnId, time.Now().Unix()); err != nil {
+			return err
+		}
+	}
+
+	if len(jobErrs) > 0 {
+		return fmt.Errorf("backfill done with %d of %d stories incomplete, run it again to retry them: %w",
+			len(jobErrs), len(stories), errors.Join(jobErrs...))
+	}
+	log.Printf("backfill done: %d stories, %d completed by previous backfills", len(stories), completed)
+	return nil
+}
+
+// ensureStory creates the story of sub if it isn't stored yet.
+func (s *SyncProcess) ensureStory(sub BackfillSubmission) error {
+	_, err := s.store.GetStory(sub.HnId)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	log.Printf("creating %q story %d (%s)", sub.Kind.Label(), sub.HnId, sub.Month)
+	return s.store.CreateStory(&HnStory{HnId: sub.HnId, Title: sub.Title, Time: sub.Time, Kind: sub.Kind})
+}
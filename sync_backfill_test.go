@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStoryMonth(t *testing.T) {
+	oct := uint64(time.Date(2026, time.October, 1, 15, 0, 0, 0, time.UTC).Unix())
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{name: "title_month", title: "Ask HN: Who is hiring? (January 2020)", expected: "2020-01"},
+		{name: "lowercase_title_month", title: "Ask HN: Who is hiring? (march 2015)", expected: "2015-03"},
+		{name: "posted_month", title: "Ask HN: Who is hiring?", expected: "2026-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := storyMonth(tt.title, oct); res != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, res)
+			}
+		})
+	}
+}
+
+// backfillTestServer serves the whoishiring submissions of three months,
+// with one job per thread. onRequest is passed to newFakeHN.
+func backfillTestServer(t *testing.T, onRequest func(path string) int) *fakeHN {
+	t.Helper()
+
+	posted := func(month time.Month) uint64 {
+		return uint64(time.Date(2026, month, 1, 15, 0, 0, 0, time.UTC).Unix())
+	}
+	items := map[uint64]any{
+		105: ApiStory{Id: 105, Title: "Ask HN: Who is hiring? (November 2026)", Time: posted(time.November), Kids: []uint64{51}},
+		104: ApiStory{Id: 104, Title: "Ask HN: Who wants to be hired? (November 2026)", Time: posted(time.November) - 60, Kids: []uint64{41}},
+		103: ApiStory{Id: 103, Title: "Ask HN: Who is hiring? (October 2026)", Time: posted(time.October), Kids: []uint64{31}},
+		101: ApiStory{Id: 101, Title: "Ask HN: Who is hiring? (September 2026)", Time: posted(time.September), Kids: []uint64{11}},
+		51:  ApiJob{Id: 51, Text: "Acme | Go | Remote"},
+		41:  ApiJob{Id: 41, Text: "Location: Berlin | Remote: Yes"},
+		31:  ApiJob{Id: 31, Text: "Initech | Rust | Berlin"},
+		11:  ApiJob{Id: 11, Text: "Globex | Python | Onsite"},
+	}
+
+	return newFakeHN(t, []uint64{105, 104, 103, 102, 101}, items, onRequest)
+}
+
+func TestSyncProcess_Backfill(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := &HNStore{db: db}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var interrupt atomic.Bool
+	interrupt.Store(true)
+	server := backfillTestServer(t, func(path string) int {
+		// the first backfill is interrupted while fetching the October jobs
+		if interrupt.Load() && path == "/item/31.json" {
+			cancel()
+		}
+		return 0
+	})
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	sp := NewSyncProcess(store, client, NewFetcher(client, 1, 0))
+	since := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	err := sp.Backfill(ctx, since)
+	if err == nil || !strings.Contains(err.Error(), "backfill interrupted at story 103") {
+		t.Fatalf("expected backfill to be interrupted at story 103, got %v", err)
+	}
+	if job := queryTestJobById(t, store, 51); job.Status != jobStatusOk {
+		t.Fatalf("expected job 51 to be fetched before the interruption")
+	}
+
+	interrupt.Store(false)
+	server.Requests()
+	if err := sp.Backfill(context.Background(), since); err != nil {
+		t.Fatalf("Backfill() failed: %v", err)
+	}
+
+	// The checked submissions and the completed November story are skipped.
+	expected := []string{"/user/whoishiring.json", "/item/103.json", "/item/31.json"}
+	if requests := server.Requests(); strings.Join(requests, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+
+	stories, err := store.GetStoriesWithStats()
+	if err != nil {
+		t.Fatalf("GetStoriesWithStats() failed: %v", err)
+	}
+	var ids []string
+	for _, s := range stories {
+		ids = append(ids, fmt.Sprintf("%d:%s", s.HnId, s.Kind))
+	}
+	if fmt.Sprint(ids) != "[105:hiring 104:wants-to-be-hired 103:hiring]" {
+		t.Errorf("expected the November and October stories, got %v", ids)
+	}
+	if job := queryTestJobById(t, store, 41); job.Status != jobStatusOk {
+		t.Errorf("expected job 41 to be fetched")
+	}
+	if job := queryTestJobById(t, store, 31); job.Status != jobStatusOk {
+		t.Errorf("expected job 31 to be fetched")
+	}
+
+	if err := sp.Backfill(context.Background(), since); err != nil {
+		t.Fatalf("Backfill() failed: %v", err)
+	}
+	if requests := server.Requests(); len(requests) != 1 {
+		t.Errorf("expected only the submissions to be fetched again, got %v", requests)
+	}
+}
+
+func TestSyncProcess_Backfill_retriesFailedJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	store := &HNStore{db: db}
+
+	var failing atomic.Bool
+	failing.Store(true)
+	server := backfillTestServer(t, func(path string) int {
+		if failing.Load() && path == "/item/31.json" {
+			return http.StatusInternalServerError
+		}
+		return 0
+	})
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	sp := NewSyncProcess(store, client, NewFetcher(client, 2, 0))
+	since := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	err := sp.Backfill(context.Background(), since)
+	if err == nil || !strings.Contains(err.Error(), "failed to get job 31") {
+		t.Fatalf("expected job 31 to fail, got %v", err)
+	}
+	if job := queryTestJobById(t, store, 51); job.Status != jobStatusOk {
+		t.Errorf("expected the jobs of the other stories to be fetched")
+	}
+	checked, err := store.GetBackfillSubmissions()
+	if err != nil {
+		t.Fatalf("GetBackfillSubmissions() failed: %v", err)
+	}
+	if checked[103].CompletedAt != nil || checked[105].CompletedAt == nil {
+		t.Fatalf("expected only story 103 to be incomplete, got %+v and %+v", checked[103], checked[105])
+	}
+
+	failing.Store(false)
+	if err := sp.Backfill(context.Background(), since); err != nil {
+		t.Fatalf("Backfill() failed: %v", err)
+	}
+	if job := queryTestJobById(t, store, 31); job.Status != jobStatusOk {
+		t.Errorf("expected job 31 to be fetched by the second backfill")
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestReconcileJobIds(t *testing.T) {
+	saved := map[uint64]uint8{1: jobStatusOk, 2: jobStatusOk, 3: jobStatusDead, 6: jobStatusRemoved, 7: jobStatusRemoved}
+	kids := []uint64{5, 3, 0, 2, 4, 6}
+
+	newIds, removedIds, restoredIds, unchanged := reconcileJobIds(saved, kids)
+	if !reflect.DeepEqual(newIds, []uint64{5, 4}) {
+		t.Errorf("expected new ids [5 4], got %v", newIds)
+	}
+	if !reflect.DeepEqual(removedIds, []uint64{1, 7}) {
+		t.Errorf("expected removed ids [1 7], got %v", removedIds)
+	}
+	if !reflect.DeepEqual(restoredIds, []uint64{6}) {
+		t.Errorf("expected restored ids [6], got %v", restoredIds)
+	}
+	if unchanged != 2 {
+		t.Errorf("expected 2 unchanged ids, got %d", unchanged)
+	}
+}
+
+func TestSyncProcess_getNewJobs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	if err := store.CreateJob(&HnJob{HnId: 2, Text: "test job 2", Status: jobStatusOk}, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+	if err := store.CreateJob(&HnJob{HnId: 4, Text: "test job 4", Status: jobStatusRemoved}, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+
+	// Job 1 is no longer a kid of the story, job 3 is new and job 4 is a
+	// kid again.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/item/1.json":
+			json.NewEncoder(w).Encode(ApiStory{Id: story.HnId, Title: story.Title, Kids: []uint64{4, 3, 2}})
+		case "/item/3.json":
+			json.NewEncoder(w).Encode(ApiJob{Id: 3, Text: "Acme | Go Engineer | Remote"})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	sp := NewSyncProcess(store, client, NewFetcher(client, 2, 0))
+	if err := sp.getNewJobs(context.Background(), story.HnId); err != nil {
+		t.Fatalf("getNewJobs() failed: %v", err)
+	}
+
+	if removed := queryTestJobById(t, store, job.HnId); removed.Status != jobStatusRemoved {
+		t.Errorf("expected job %d to be removed, got status %d", job.HnId, removed.Status)
+	}
+	if kept := queryTestJobById(t, store, 2); kept.Status != jobStatusOk {
+		t.Errorf("expected job 2 to stay OK, got status %d", kept.Status)
+	}
+	if added := queryTestJobById(t, store, 3); added.Status != jobStatusOk {
+		t.Errorf("expected job 3 to be added, got status %d", added.Status)
+	}
+
+	ids, err := store.GetOkJobIdsByStoryId(story.HnId)
+	if err != nil {
+		t.Fatalf("GetOkJobIdsByStoryId() failed: %v", err)
+	}
+	if keys := slices.Sorted(maps.Keys(ids)); !reflect.DeepEqual(keys, []uint64{2, 3, 4}) {
+		t.Errorf("expected OK jobs [2 3 4], got %v", keys)
+	}
+}
+
+func TestSyncProcess_getNewJobs_unreliableStory(t *testing.T) {
+	tests := []struct {
+		name  string
+		story ApiStory
+	}{
+		{name: "dead", story: ApiStory{Id: 1, Dead: true, Kids: []uint64{2}}},
+		{name: "deleted", story: ApiStory{Id: 1, Deleted: true}},
+		{name: "no_kids", story: ApiStory{Id: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			defer db.Close()
+
+			store := &HNStore{db: db}
+			story, job := setUpStoryWithJob(t, store)
+
+			server := newFakeHN(t, nil, map[uint64]any{story.HnId: tt.story}, nil)
+			defer server.Close()
+
+			client := NewClient(server.URL)
+			sp := NewSyncProcess(store, client, NewFetcher(client, 2, 0))
+			if err := sp.getNewJobs(context.Background(), story.HnId); err != nil {
+				t.Fatalf("getNewJobs() failed: %v", err)
+			}
+
+			if kept := queryTestJobById(t, store, job.HnId); kept.Status != jobStatusOk {
+				t.Errorf("expected job %d to stay OK, got status %d", job.HnId, kept.Status)
+			}
+		})
+	}
+}
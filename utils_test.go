@@ -1,31 +1,30 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/pressly/goose/v3"
 )
 
-// setupTestDB creates an in-memory sqlite3 database and applies available migrations.
+// setupTestDB creates an in-memory sqlite3 database and applies the embedded migrations.
 func setupTestDB(t *testing.T) *sqlx.DB {
-	migrationsPath := "./migrations"
-
 	db, err := sqlx.Open("sqlite3", ":memory:")
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	goose.SetLogger(goose.NopLogger())
-
-	err = goose.SetDialect(db.DriverName())
-	if err != nil {
-		t.Fatalf("failed to set dialect: %v", err)
-	}
+	// every connection to :memory: is a new database
+	db.SetMaxOpenConns(1)
 
-	err = goose.Up(db.DB, migrationsPath)
-	if err != nil {
+	if _, err := migrateUp(context.Background(), db); err != nil {
 		t.Fatalf("failed to run migrations: %v", err)
 	}
 
@@ -68,3 +67,61 @@ func queryTestJobById(t *testing.T, store *HNStore, jobId uint64) *HnJob {
 
 	return &job
 }
+
+// fakeHN is a fake Hacker News API serving the submissions of the
+// whoishiring user and the items of a fixture. Items missing from the
+// fixture are served as null, like deleted items.
+type fakeHN struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+// newFakeHN starts a fakeHN. If onRequest isn't nil, it is called with the
+// path of every request, and the status code it returns is used as the
+// response unless it's 0.
+func newFakeHN(t *testing.T, submitted []uint64, items map[uint64]any, onRequest func(path string) int) *fakeHN {
+	t.Helper()
+
+	f := &fakeHN{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.requests = append(f.requests, r.URL.Path)
+		f.mu.Unlock()
+
+		if onRequest != nil {
+			if code := onRequest(r.URL.Path); code != 0 {
+				http.Error(w, http.StatusText(code), code)
+				return
+			}
+		}
+		if r.URL.Path == "/user/whoishiring.json" {
+			json.NewEncoder(w).Encode(map[string]any{"submitted": submitted})
+			return
+		}
+
+		var id uint64
+		if _, err := fmt.Sscanf(r.URL.Path, "/item/%d.json", &id); err != nil {
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		item, ok := items[id]
+		if !ok {
+			w.Write([]byte("null"))
+			return
+		}
+		json.NewEncoder(w).Encode(item)
+	}))
+	return f
+}
+
+// Requests returns the paths requested so far, and forgets them.
+func (f *fakeHN) Requests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requests := slices.Clone(f.requests)
+	f.requests = nil
+	return requests
+}
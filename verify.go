@@ -1,64 +1,192 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log"
-	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// VerifyTarget selects the OK jobs checked by a VerifyProcess. The zero value
+// targets the jobs of the latest story.
+type VerifyTarget struct {
+	// StoryId targets the jobs of a single story.
+	StoryId uint64
+	// From and To target the jobs of the stories posted between both dates,
+	// inclusive. Either may be zero to leave the range open.
+	From, To time.Time
+	// Stalest targets the N jobs that haven't been verified for the longest.
+	Stalest int
+}
+
+// IsZero returns true if no target is set.
+func (t VerifyTarget) IsZero() bool {
+	return t == VerifyTarget{}
+}
+
+func (t VerifyTarget) String() string {
+	switch {
+	case t.Stalest > 0:
+		return fmt.Sprintf("stalest %d jobs", t.Stalest)
+	case !t.From.IsZero() || !t.To.IsZero():
+		format := func(d time.Time) string {
+			if d.IsZero() {
+				return "…"
+			}
+			return d.Format(time.DateOnly)
+		}
+		return fmt.Sprintf("stories %s to %s", format(t.From), format(t.To))
+	default:
+		return fmt.Sprintf("story %d", t.StoryId)
+	}
+}
+
 type VerifyProcess struct {
-	store  *HNStore
-	client *Client
+	store   *HNStore
+	fetcher *Fetcher
 }
 
-func NewVerifyProcess(store *HNStore, client *Client) *VerifyProcess {
+func NewVerifyProcess(store *HNStore, fetcher *Fetcher) *VerifyProcess {
 	return &VerifyProcess{
-		store:  store,
-		client: client,
+		store:   store,
+		fetcher: fetcher,
 	}
 }
 
-func (v *VerifyProcess) Run() error {
+// Run updates the status of the targeted OK jobs and records the edits made
+// to them. The results are saved as a verify run. It stops when ctx is
+// cancelled, keeping the updates made so far.
+func (v *VerifyProcess) Run(ctx context.Context, target VerifyTarget) error {
 	log.Println("starting verify process...")
 
-	latestStory, err := v.store.GetLatestStory()
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("GetLatestStory() returned zero rows.")
+	if target.IsZero() {
+		latestStory, err := v.store.GetLatestStory()
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("GetLatestStory() returned zero rows.")
+			}
+			return fmt.Errorf("failed to get latest hiring story: %w", err)
 		}
-		return fmt.Errorf("failed to get latest hiring story: %w", err)
+		log.Printf("latest hiring story id: %d", latestStory.HnId)
+		target.StoryId = latestStory.HnId
 	}
-	log.Printf("latest hiring story id: %d", latestStory.HnId)
 
-	jobs, err := v.store.GetOkJobIdsByStoryId(latestStory.HnId)
+	ids, err := v.store.GetJobIdsToVerify(target)
 	if err != nil {
 		return fmt.Errorf("failed to get job ids with OK status: %w", err)
 	}
-	log.Printf("found %d jobs with OK status", len(jobs))
+	log.Printf("found %d jobs with OK status in %s", len(ids), target)
+
+	run := &VerifyRun{Target: target.String(), StartedAt: time.Now().Unix()}
+	if err := v.store.CreateVerifyRun(run); err != nil {
+		return err
+	}
 
-	var wg sync.WaitGroup
+	var checked, changed, edited, failed atomic.Uint64
+	err = v.fetcher.FetchJobs(ctx, ids, func(jobId uint64, j *ApiJob, err error) {
+		// Jobs that no longer exist are what verify is looking for, so only
+		// other errors count as failed.
+		var hnStatus uint8 = jobStatusDeleted
+		switch {
+		case errors.Is(err, ErrNotFound) || errors.Is(err, ErrNullItem):
+			log.Printf("job id %d doesn't exist", jobId)
+		case err != nil:
+			failed.Add(1)
+			log.Println(err)
+			return
+		default:
+			hnStatus = j.StatusToDbValue()
+		}
+		checked.Add(1)
+		if err := v.store.SetJobVerified(jobId, time.Now().Unix()); err != nil {
+			log.Println(err)
+		}
 
-	for jobId := range jobs {
-		wg.Go(func() {
-			j, err := v.client.GetJob(jobId)
+		if hnStatus != jobStatusOk {
+			err := v.store.SetJobStatus(jobId, hnStatus)
 			if err != nil {
 				log.Println(err)
 				return
 			}
-			hnStatus := j.StatusToDbValue()
-			if hnStatus != jobStatusOk {
-				err := v.store.SetJobStatus(jobId, hnStatus)
-				if err != nil {
-					log.Println(err)
-					return
-				}
-				log.Printf("job id %d is NOT OK, updated status to %d", jobId, hnStatus)
+			changed.Add(1)
+			if err := v.store.AddVerifyRunJob(run.Id, jobId, hnStatus); err != nil {
+				log.Println(err)
+			}
+			log.Printf("job id %d is NOT OK, updated status to %d", jobId, hnStatus)
+			return
+		}
+
+		isEdited, err := v.store.UpdateJobText(jobId, j.Text)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		if isEdited {
+			edited.Add(1)
+			if err := v.store.SaveJobHeader(jobId, ParseJobHeader(j.Text)); err != nil {
+				log.Printf("failed to save job header %d: %v", jobId, err)
 			}
-		})
+			log.Printf("job id %d was edited, saved previous version", jobId)
+		}
+	})
+
+	run.FinishedAt = time.Now().Unix()
+	run.Checked = checked.Load()
+	run.Changed = changed.Load()
+	run.Edited = edited.Load()
+	run.Failed = failed.Load()
+	run.Interrupted = err != nil
+	if finishErr := v.store.FinishVerifyRun(run); finishErr != nil {
+		log.Println(finishErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("verify interrupted after checking %d of %d jobs: %w", run.Checked, len(ids), err)
 	}
 
-	wg.Wait()
+	log.Printf("checked %d of %d jobs: %d no longer OK, %d edited, %d failed",
+		run.Checked, len(ids), run.Changed, run.Edited, run.Failed)
+	return nil
+}
+
+// printVerifyRuns prints the latest verify runs along with the jobs whose
+// status they changed.
+func printVerifyRuns(store *HNStore, w io.Writer, limit int) error {
+	runs, err := store.GetVerifyRuns(limit)
+	if err != nil {
+		return err
+	}
+
+	if len(runs) == 0 {
+		fmt.Fprintln(w, "No verify runs found.")
+		return nil
+	}
+
+	for _, r := range runs {
+		started := time.Unix(r.StartedAt, 0).UTC().Format("2006-01-02 15:04")
+		fmt.Fprintf(w, "%s  %s: checked %d, %d no longer OK, %d edited, %d failed",
+			started, r.Target, r.Checked, r.Changed, r.Edited, r.Failed)
+		if r.Interrupted {
+			fmt.Fprint(w, " (interrupted)")
+		}
+		fmt.Fprintln(w)
+
+		jobs, err := store.GetVerifyRunJobs(r.Id)
+		if err != nil {
+			return err
+		}
+		for _, j := range jobs {
+			saved := ""
+			if j.Saved == 1 {
+				saved = ", saved"
+			}
+			fmt.Fprintf(w, "  job %d %s (%s%s)\n", j.HnId, jobStatusName(j.Status), j.StoryTitle, saved)
+		}
+	}
 
 	return nil
 }
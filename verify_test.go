@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVerifyTarget(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		storyId  uint64
+		from, to string
+		stalest  int
+		expected VerifyTarget
+		wantErr  bool
+	}{
+		{name: "latest_story", expected: VerifyTarget{}},
+		{name: "story", storyId: 10, expected: VerifyTarget{StoryId: 10}},
+		{name: "date_range", from: "2026-01-01", to: "2026-03-31", expected: VerifyTarget{From: day("2026-01-01"), To: day("2026-03-31")}},
+		{name: "open_date_range", from: "2026-01-01", expected: VerifyTarget{From: day("2026-01-01")}},
+		{name: "stalest", stalest: 100, expected: VerifyTarget{Stalest: 100}},
+		{name: "invalid_date", from: "2026-13-01", wantErr: true},
+		{name: "reversed_dates", from: "2026-03-01", to: "2026-01-01", wantErr: true},
+		{name: "negative_stalest", stalest: -1, wantErr: true},
+		{name: "several_targets", storyId: 10, stalest: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := parseVerifyTarget(tt.storyId, tt.from, tt.to, tt.stalest)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got target %+v", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(target, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, target)
+			}
+		})
+	}
+}
+
+func TestVerifyProcess_Run(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &HNStore{db: db}
+	story, job := setUpStoryWithJob(t, store)
+	if err := store.CreateJob(&HnJob{HnId: 2, Text: "test job 2", Status: jobStatusOk}, story.HnId); err != nil {
+		t.Fatalf("CreateJob() failed: %v", err)
+	}
+	if err := store.SetJobSaved(2); err != nil {
+		t.Fatalf("SetJobSaved() failed: %v", err)
+	}
+	for _, id := range []uint64{3, 4, 5} {
+		if err := store.CreateJob(&HnJob{HnId: id, Text: job.Text, Status: jobStatusOk}, story.HnId); err != nil {
+			t.Fatalf("CreateJob() failed: %v", err)
+		}
+	}
+
+	// Job 1 is unchanged, job 2 is dead, jobs 3 and 4 no longer exist and
+	// job 5 can't be fetched.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/item/"), ".json"), 10, 64)
+		switch id {
+		case 3:
+			w.Write([]byte("null"))
+		case 4:
+			http.NotFound(w, r)
+		case 5:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		default:
+			json.NewEncoder(w).Encode(ApiJob{Id: id, Text: job.Text, Dead: id == 2})
+		}
+	}))
+	defer server.Close()
+
+	v := NewVerifyProcess(store, NewFetcher(newTestClient(server.URL), 2, 0))
+	if err := v.Run(context.Background(), VerifyTarget{}); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if j := queryTestJobById(t, store, 2); j.Status != jobStatusDead {
+		t.Errorf("expected job 2 to be dead, got status %d", j.Status)
+	}
+	for _, id := range []uint64{3, 4} {
+		if j := queryTestJobById(t, store, id); j.Status != jobStatusDeleted {
+			t.Errorf("expected job %d to be deleted, got status %d", id, j.Status)
+		}
+	}
+	if j := queryTestJobById(t, store, 5); j.Status != jobStatusOk {
+		t.Errorf("expected job 5 to stay OK, got status %d", j.Status)
+	}
+
+	var lastVerified []int64
+	if err := db.Select(&lastVerified, `SELECT last_verified_at FROM hiring_job ORDER BY hn_id`); err != nil {
+		t.Fatalf("failed to select last_verified_at: %v", err)
+	}
+	for i, at := range lastVerified[:4] {
+		if at == 0 {
+			t.Errorf("expected job %d to have last_verified_at set", i+1)
+		}
+	}
+
+	runs, err := store.GetVerifyRuns(10)
+	if err != nil {
+		t.Fatalf("GetVerifyRuns() failed: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 verify run, got %d", len(runs))
+	}
+	r := runs[0]
+	if r.Target != "story 1" || r.Checked != 4 || r.Changed != 3 || r.Failed != 1 || r.Interrupted || r.FinishedAt == 0 {
+		t.Errorf("unexpected verify run %+v", r)
+	}
+
+	var out bytes.Buffer
+	if err := printVerifyRuns(store, &out, 10); err != nil {
+		t.Fatalf("printVerifyRuns() failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "job 2 dead (test story 1, saved)") {
+		t.Errorf("expected output to list the dead saved job, got %q", out.String())
+	}
+}